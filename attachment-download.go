@@ -0,0 +1,373 @@
+// Copyright © 2020 Mike Berezin
+//
+// Use of this source code is governed by an MIT license.
+// Details in the LICENSE file.
+
+package airtable
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+const (
+	defaultDownloadConcurrency = 4
+	downloadIndexFile          = "index.json"
+	downloadObjectsDir         = "objects"
+)
+
+// ErrSizeMismatch returned when downloaded attachment size
+// differs from FieldAttachmentDetails.Size.
+var ErrSizeMismatch = errors.New("downloaded size does not match attachment size")
+
+// AttachmentDownloader mirrors attachments to a local content-addressed directory.
+// Attachment URLs expire after a few hours, so download them soon after reading records.
+//
+// Files are stored as objects/<sha256[:2]>/<sha256>, index.json maps
+// attachment IDs (and "<id>/small", "<id>/large", "<id>/full" for thumbnails) to the stored files.
+// Airtable attachment IDs do not change with the content,
+// so attachments already in the index are skipped.
+type AttachmentDownloader struct {
+	client      *http.Client
+	dir         string
+	concurrency int
+	thumbnails  bool
+
+	mu    sync.Mutex
+	index map[string]*DownloadedFile
+}
+
+// DownloadedFile index entry of the stored attachment.
+type DownloadedFile struct {
+	Hash     string `json:"hash"`
+	Size     int64  `json:"size"`
+	FileName string `json:"filename,omitempty"`
+	Type     string `json:"type,omitempty"`
+}
+
+// DownloadResult result of one attachment or thumbnail download.
+type DownloadResult struct {
+	AttachmentID string
+	// Thumbnail is "small", "large" or "full" for thumbnails and empty for the file.
+	Thumbnail string
+	// Path of the stored file.
+	Path    string
+	Skipped bool
+	Err     error
+}
+
+// NewAttachmentDownloader creates downloader to the dir
+// using the client's http client.
+func (at *Client) NewAttachmentDownloader(dir string) *AttachmentDownloader {
+	return &AttachmentDownloader{
+		client:      at.client,
+		dir:         dir,
+		concurrency: defaultDownloadConcurrency,
+	}
+}
+
+// WithConcurrency set number of parallel downloads, 4 by default.
+func (d *AttachmentDownloader) WithConcurrency(concurrency int) *AttachmentDownloader {
+	d.concurrency = max(concurrency, 1)
+	return d
+}
+
+// WithThumbnails download thumbnails along with the files.
+func (d *AttachmentDownloader) WithThumbnails() *AttachmentDownloader {
+	d.thumbnails = true
+	return d
+}
+
+// Index returns stored file of the attachment or thumbnail index key.
+func (d *AttachmentDownloader) Index(key string) (*DownloadedFile, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err := d.loadIndex(); err != nil {
+		return nil, false
+	}
+	file, ok := d.index[key]
+	return file, ok
+}
+
+// Open opens stored file of the attachment or thumbnail index key.
+func (d *AttachmentDownloader) Open(key string) (*os.File, *DownloadedFile, error) {
+	file, ok := d.Index(key)
+	if !ok {
+		return nil, nil, fmt.Errorf("attachment %s: %w", key, os.ErrNotExist)
+	}
+	f, err := os.Open(d.objectPath(file.Hash))
+	if err != nil {
+		return nil, nil, err
+	}
+	return f, file, nil
+}
+
+// DownloadRecords downloads attachments of the record fields.
+// All attachment fields are downloaded if no field names passed.
+func (d *AttachmentDownloader) DownloadRecords(ctx context.Context, records []*Record, fieldNames ...string) ([]DownloadResult, error) {
+	var attachments []FieldAttachmentDetails
+	for _, record := range records {
+		names := fieldNames
+		if len(names) == 0 {
+			names = make([]string, 0, len(record.Fields))
+			for name := range record.Fields {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+		}
+		for _, name := range names {
+			details, err := ToAttachments(record.Fields[name])
+			if err != nil {
+				if len(fieldNames) > 0 {
+					return nil, fieldError(name, err)
+				}
+				continue
+			}
+			for _, a := range details {
+				if a.Id != "" && a.URL != "" {
+					attachments = append(attachments, a)
+				}
+			}
+		}
+	}
+	return d.Download(ctx, attachments)
+}
+
+// DownloadFieldAttachments downloads attachments returned by UploadAttachment.
+func (d *AttachmentDownloader) DownloadFieldAttachments(ctx context.Context, fieldAttachments *FieldAttachments) ([]DownloadResult, error) {
+	names := make([]string, 0, len(fieldAttachments.Attachments))
+	for name := range fieldAttachments.Attachments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var attachments []FieldAttachmentDetails
+	for _, name := range names {
+		attachments = append(attachments, fieldAttachments.Attachments[name]...)
+	}
+	return d.Download(ctx, attachments)
+}
+
+// downloadTask one file to download.
+type downloadTask struct {
+	key       string
+	thumbnail string
+	url       string
+	size      int64
+	details   FieldAttachmentDetails
+}
+
+// Download downloads attachments with limited concurrency.
+// Per-file errors are reported in results, the returned error is
+// only for failures of the whole download like unwritable directory.
+func (d *AttachmentDownloader) Download(ctx context.Context, attachments []FieldAttachmentDetails) ([]DownloadResult, error) {
+	d.mu.Lock()
+	err := d.loadIndex()
+	d.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := d.tasks(attachments)
+	results := make([]DownloadResult, len(tasks))
+
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for range min(d.concurrency, len(tasks)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				results[i] = d.download(ctx, tasks[i])
+			}
+		}()
+	}
+	for i, task := range tasks {
+		if ctx.Err() != nil {
+			results[i] = DownloadResult{AttachmentID: task.details.Id, Thumbnail: task.thumbnail, Err: ctx.Err()}
+			continue
+		}
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			results[i] = DownloadResult{AttachmentID: task.details.Id, Thumbnail: task.thumbnail, Err: ctx.Err()}
+		}
+	}
+	close(indexes)
+	wg.Wait()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err := d.saveIndex(); err != nil {
+		return results, err
+	}
+
+	return results, nil
+}
+
+func (d *AttachmentDownloader) tasks(attachments []FieldAttachmentDetails) []downloadTask {
+	var tasks []downloadTask
+	seen := map[string]struct{}{}
+	add := func(task downloadTask) {
+		if _, ok := seen[task.key]; ok || task.url == "" {
+			return
+		}
+		seen[task.key] = struct{}{}
+		tasks = append(tasks, task)
+	}
+
+	for _, a := range attachments {
+		add(downloadTask{key: a.Id, url: a.URL, size: int64(a.Size), details: a})
+		if !d.thumbnails || a.Thumbnails == nil {
+			continue
+		}
+		for name, thumbnail := range map[string]*AttachmentThumbnail{
+			"small": a.Thumbnails.Small,
+			"large": a.Thumbnails.Large,
+			"full":  a.Thumbnails.Full,
+		} {
+			if thumbnail != nil {
+				add(downloadTask{key: a.Id + "/" + name, thumbnail: name, url: thumbnail.URL, details: a})
+			}
+		}
+	}
+
+	sort.SliceStable(tasks, func(i, j int) bool { return tasks[i].key < tasks[j].key })
+	return tasks
+}
+
+func (d *AttachmentDownloader) download(ctx context.Context, task downloadTask) DownloadResult {
+	result := DownloadResult{AttachmentID: task.details.Id, Thumbnail: task.thumbnail}
+
+	d.mu.Lock()
+	stored, ok := d.index[task.key]
+	d.mu.Unlock()
+	if ok {
+		path := d.objectPath(stored.Hash)
+		if _, err := os.Stat(path); err == nil {
+			result.Path = path
+			result.Skipped = true
+			return result
+		}
+	}
+
+	file, err := d.fetch(ctx, task)
+	if err != nil {
+		result.Err = fmt.Errorf("attachment %s: %w", task.key, err)
+		return result
+	}
+
+	d.mu.Lock()
+	d.index[task.key] = file
+	d.mu.Unlock()
+
+	result.Path = d.objectPath(file.Hash)
+	return result
+}
+
+// fetch downloads file to a temporary file and moves it to the object path.
+func (d *AttachmentDownloader) fetch(ctx context.Context, task downloadTask) (*DownloadedFile, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, task.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create request: %w", err)
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("HTTP request failure: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, makeHTTPClientError(req.URL.Path, resp)
+	}
+
+	tmp, err := os.CreateTemp(d.dir, "download-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp.Name())
+
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, hash), resp.Body)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot download: %w", err)
+	}
+
+	if task.size > 0 && size != task.size {
+		return nil, fmt.Errorf("%w: got %d bytes, expected %d", ErrSizeMismatch, size, task.size)
+	}
+
+	file := &DownloadedFile{
+		Hash:     hex.EncodeToString(hash.Sum(nil)),
+		Size:     size,
+		FileName: task.details.FileName,
+		Type:     task.details.Type,
+	}
+	if task.thumbnail != "" {
+		file.Type = resp.Header.Get("Content-Type")
+	}
+
+	path := d.objectPath(file.Hash)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return nil, err
+	}
+
+	return file, nil
+}
+
+func (d *AttachmentDownloader) objectPath(hash string) string {
+	return filepath.Join(d.dir, downloadObjectsDir, hash[:2], hash)
+}
+
+// loadIndex reads index from dir once, must be called with mu locked.
+func (d *AttachmentDownloader) loadIndex() error {
+	if d.index != nil {
+		return nil
+	}
+	if err := os.MkdirAll(d.dir, 0o755); err != nil {
+		return fmt.Errorf("cannot create download dir: %w", err)
+	}
+
+	index := map[string]*DownloadedFile{}
+	b, err := os.ReadFile(filepath.Join(d.dir, downloadIndexFile))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("cannot read download index: %w", err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(b, &index); err != nil {
+			return fmt.Errorf("cannot decode download index: %w", err)
+		}
+	}
+	d.index = index
+	return nil
+}
+
+// saveIndex writes index atomically, must be called with mu locked.
+func (d *AttachmentDownloader) saveIndex() error {
+	b, err := json.MarshalIndent(d.index, "", "  ")
+	if err != nil {
+		return fmt.Errorf("cannot encode download index: %w", err)
+	}
+	path := filepath.Join(d.dir, downloadIndexFile)
+	if err := os.WriteFile(path+".tmp", b, 0o644); err != nil {
+		return fmt.Errorf("cannot write download index: %w", err)
+	}
+	return os.Rename(path+".tmp", path)
+}
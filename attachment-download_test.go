@@ -0,0 +1,142 @@
+// Copyright © 2020 Mike Berezin
+//
+// Use of this source code is governed by an MIT license.
+// Details in the LICENSE file.
+
+package airtable
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+)
+
+func TestAttachmentDownloader_DownloadRecords(t *testing.T) {
+	var mu sync.Mutex
+	requests := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests[r.URL.Path]++
+		mu.Unlock()
+		switch r.URL.Path {
+		case "/missing.png":
+			http.NotFound(rw, r)
+		default:
+			_, _ = rw.Write([]byte("content of " + r.URL.Path))
+		}
+	}))
+	defer server.Close()
+
+	records := []*Record{
+		{ID: "rec1", Fields: map[string]any{
+			"Name": "Name",
+			"Pictures": []any{
+				map[string]any{
+					"id": "att1", "url": server.URL + "/1.png", "filename": "1.png", "size": float64(len("content of /1.png")),
+					"thumbnails": map[string]any{"small": map[string]any{"url": server.URL + "/1-small.png"}},
+				},
+				map[string]any{"id": "att2", "url": server.URL + "/2.png", "size": float64(1)},
+			},
+		}},
+		{ID: "rec2", Fields: map[string]any{
+			"Pictures": []any{
+				map[string]any{"id": "att1", "url": server.URL + "/1.png"},
+				map[string]any{"id": "att3", "url": server.URL + "/missing.png"},
+				map[string]any{"id": "att4", "url": server.URL + "/1-copy.png"},
+			},
+		}},
+	}
+
+	dir := t.TempDir()
+	downloader := testClient().NewAttachmentDownloader(dir).WithConcurrency(2).WithThumbnails()
+	results, err := downloader.DownloadRecords(context.Background(), records)
+	if err != nil {
+		t.Fatalf("must be no error, but was: %v", err)
+	}
+	if len(results) != 5 {
+		t.Fatalf("should be 5 results for unique attachments and thumbnail, but was: %v", results)
+	}
+
+	byKey := map[string]DownloadResult{}
+	for _, result := range results {
+		key := result.AttachmentID
+		if result.Thumbnail != "" {
+			key += "/" + result.Thumbnail
+		}
+		byKey[key] = result
+	}
+	content, err := os.ReadFile(byKey["att1"].Path)
+	if err != nil || string(content) != "content of /1.png" {
+		t.Errorf("att1 should be stored, but was: %q, %v", content, err)
+	}
+	if byKey["att1/small"].Err != nil || byKey["att1/small"].Path == "" {
+		t.Errorf("thumbnail should be stored, but was: %#v", byKey["att1/small"])
+	}
+	if !errors.Is(byKey["att2"].Err, ErrSizeMismatch) {
+		t.Errorf("att2 should fail with size mismatch, but was: %v", byKey["att2"].Err)
+	}
+	var httpErr *HTTPClientError
+	if !errors.As(byKey["att3"].Err, &httpErr) {
+		t.Errorf("att3 should fail with http error, but was: %v", byKey["att3"].Err)
+	}
+	if byKey["att4"].Path == byKey["att1"].Path {
+		t.Errorf("different content should be stored in different files")
+	}
+
+	// new downloader reads the index and skips stored attachments
+	downloader = testClient().NewAttachmentDownloader(dir)
+	results, err = downloader.DownloadRecords(context.Background(), records, "Pictures")
+	if err != nil {
+		t.Fatalf("must be no error, but was: %v", err)
+	}
+	for _, result := range results {
+		if (result.AttachmentID == "att1" || result.AttachmentID == "att4") && !result.Skipped {
+			t.Errorf("stored attachment should be skipped, but was: %#v", result)
+		}
+	}
+	if requests["/1.png"] != 1 || requests["/2.png"] != 2 {
+		t.Errorf("only failed attachments should be downloaded again, but requests were: %v", requests)
+	}
+	if _, ok := downloader.Index("att1"); !ok {
+		t.Errorf("att1 should be in index")
+	}
+	f, file, err := downloader.Open("att1")
+	if err != nil || file.FileName != "1.png" {
+		t.Fatalf("att1 should be opened, but was: %v, %v", file, err)
+	}
+	content, _ = io.ReadAll(f)
+	f.Close()
+	if string(content) != "content of /1.png" {
+		t.Errorf("unexpected content of att1: %q", content)
+	}
+	if _, _, err := downloader.Open("att3"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("should be os.ErrNotExist, but was: %v", err)
+	}
+
+	_, err = downloader.DownloadRecords(context.Background(), records, "Name")
+	if !errors.Is(err, ErrNotAttachments) {
+		t.Errorf("should be ErrNotAttachments, but was: %v", err)
+	}
+}
+
+func TestAttachmentDownloader_DownloadFieldAttachments(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		_, _ = rw.Write([]byte("content"))
+	}))
+	defer server.Close()
+
+	fieldAttachments := &FieldAttachments{
+		Attachments: map[string][]FieldAttachmentDetails{
+			"Attachments": {{Id: "att1", URL: server.URL + "/test.png", Size: 7}},
+		},
+	}
+	results, err := testClient().NewAttachmentDownloader(t.TempDir()).DownloadFieldAttachments(context.Background(), fieldAttachments)
+	if err != nil || len(results) != 1 || results[0].Err != nil {
+		t.Errorf("attachment should be downloaded, but was: %v, %v", results, err)
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright © 2020 Mike Berezin
+//
+// Use of this source code is governed by an MIT license.
+// Details in the LICENSE file.
+
+package airtable
+
+import (
+	"context"
+	"slices"
+)
+
+// Helpers below change multipleAttachments field with Set,
+// keeping existing attachments referenced by ID, otherwise Airtable deletes them.
+// Until saved the field holds attachments in the write form:
+// {"id": ...} for existing and {"url": ..., "filename": ...} for new ones.
+
+// AppendAttachments adds attachments to the end of the field.
+// New attachments are added by URL and optional FileName,
+// Airtable downloads them after save.
+func (r *Record) AppendAttachments(field string, attachments ...FieldAttachmentDetails) error {
+	current, err := r.GetAttachments(field)
+	if err != nil {
+		return err
+	}
+	r.Set(field, FromAttachments(append(current, attachments...)))
+	return nil
+}
+
+// RemoveAttachments removes attachments matched by ID or URL.
+func (r *Record) RemoveAttachments(field string, idsOrURLs ...string) error {
+	current, err := r.GetAttachments(field)
+	if err != nil {
+		return err
+	}
+	current = slices.DeleteFunc(current, func(a FieldAttachmentDetails) bool {
+		return attachmentMatches(a, idsOrURLs)
+	})
+	r.Set(field, FromAttachments(current))
+	return nil
+}
+
+// ReorderAttachments moves attachments matched by ID or URL to the beginning
+// of the field in the passed order, the rest keep their order after them.
+func (r *Record) ReorderAttachments(field string, idsOrURLs ...string) error {
+	current, err := r.GetAttachments(field)
+	if err != nil {
+		return err
+	}
+	reordered := make([]FieldAttachmentDetails, 0, len(current))
+	for _, key := range idsOrURLs {
+		for _, a := range current {
+			if attachmentMatches(a, []string{key}) && !slices.Contains(reordered, a) {
+				reordered = append(reordered, a)
+			}
+		}
+	}
+	for _, a := range current {
+		if !attachmentMatches(a, idsOrURLs) {
+			reordered = append(reordered, a)
+		}
+	}
+	r.Set(field, FromAttachments(reordered))
+	return nil
+}
+
+// AppendAttachments reads the record and adds attachments to the field
+// keeping the existing ones.
+func (t *Table) AppendAttachments(recordID, field string, attachments ...FieldAttachmentDetails) (*Record, error) {
+	return t.AppendAttachmentsContext(context.Background(), recordID, field, attachments...)
+}
+
+// AppendAttachmentsContext reads the record and adds attachments to the field
+// with custom context.
+func (t *Table) AppendAttachmentsContext(ctx context.Context, recordID, field string, attachments ...FieldAttachmentDetails) (*Record, error) {
+	return t.changeAttachments(ctx, recordID, func(record *Record) error {
+		return record.AppendAttachments(field, attachments...)
+	})
+}
+
+// RemoveAttachments reads the record and removes attachments
+// matched by ID or URL from the field.
+func (t *Table) RemoveAttachments(recordID, field string, idsOrURLs ...string) (*Record, error) {
+	return t.RemoveAttachmentsContext(context.Background(), recordID, field, idsOrURLs...)
+}
+
+// RemoveAttachmentsContext reads the record and removes attachments
+// from the field with custom context.
+func (t *Table) RemoveAttachmentsContext(ctx context.Context, recordID, field string, idsOrURLs ...string) (*Record, error) {
+	return t.changeAttachments(ctx, recordID, func(record *Record) error {
+		return record.RemoveAttachments(field, idsOrURLs...)
+	})
+}
+
+// ReorderAttachments reads the record and moves attachments
+// matched by ID or URL to the beginning of the field.
+func (t *Table) ReorderAttachments(recordID, field string, idsOrURLs ...string) (*Record, error) {
+	return t.ReorderAttachmentsContext(context.Background(), recordID, field, idsOrURLs...)
+}
+
+// ReorderAttachmentsContext reads the record and moves attachments
+// to the beginning of the field with custom context.
+func (t *Table) ReorderAttachmentsContext(ctx context.Context, recordID, field string, idsOrURLs ...string) (*Record, error) {
+	return t.changeAttachments(ctx, recordID, func(record *Record) error {
+		return record.ReorderAttachments(field, idsOrURLs...)
+	})
+}
+
+func (t *Table) changeAttachments(ctx context.Context, recordID string, change func(record *Record) error) (*Record, error) {
+	record, err := t.GetRecordContext(withoutCache(ctx), recordID)
+	if err != nil {
+		return nil, err
+	}
+	if err := change(record); err != nil {
+		return nil, err
+	}
+	if err := record.SaveContext(ctx); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+func attachmentMatches(a FieldAttachmentDetails, idsOrURLs []string) bool {
+	for _, key := range idsOrURLs {
+		if (a.Id != "" && a.Id == key) || (a.URL != "" && a.URL == key) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,130 @@
+// Copyright © 2020 Mike Berezin
+//
+// Use of this source code is governed by an MIT license.
+// Details in the LICENSE file.
+
+package airtable
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func testAttachmentsRecord(t *testing.T) *Record {
+	table := testTable()
+	table.client.baseURL = mockResponse("get_record_with_attachments.json").URL
+	record, err := table.GetRecord("recnTq6CsvFM6vX2m")
+	if err != nil {
+		t.Fatalf("must be no error, but was: %v", err)
+	}
+	return record
+}
+
+func TestRecord_AttachmentHelpers(t *testing.T) {
+	record := testAttachmentsRecord(t)
+
+	attachments, err := record.GetAttachments("Pictures")
+	if err != nil {
+		t.Fatalf("must be no error, but was: %v", err)
+	}
+	expectedThumbnails := &AttachmentThumbnails{
+		Small: &AttachmentThumbnail{URL: "https://example.com/1-small.png", Width: 72, Height: 36},
+		Large: &AttachmentThumbnail{URL: "https://example.com/1-large.png", Width: 100, Height: 50},
+	}
+	if !reflect.DeepEqual(attachments[0].Thumbnails, expectedThumbnails) || attachments[0].Width != 100 {
+		t.Errorf("thumbnails should be converted, but was: %#v", attachments[0])
+	}
+
+	err = record.AppendAttachments("Pictures", FieldAttachmentDetails{URL: "https://example.com/3.png", FileName: "3.png"})
+	if err != nil {
+		t.Fatalf("must be no error, but was: %v", err)
+	}
+	expected := map[string]any{"Pictures": []any{
+		map[string]any{"id": "att1"},
+		map[string]any{"id": "att2"},
+		map[string]any{"url": "https://example.com/3.png", "filename": "3.png"},
+	}}
+	if !reflect.DeepEqual(record.Changes(), expected) {
+		t.Errorf("expected changes: %v, but got: %v", expected, record.Changes())
+	}
+
+	if err := record.ReorderAttachments("Pictures", "https://example.com/3.png", "att2"); err != nil {
+		t.Fatalf("must be no error, but was: %v", err)
+	}
+	expected = map[string]any{"Pictures": []any{
+		map[string]any{"url": "https://example.com/3.png", "filename": "3.png"},
+		map[string]any{"id": "att2"},
+		map[string]any{"id": "att1"},
+	}}
+	if !reflect.DeepEqual(record.Changes(), expected) {
+		t.Errorf("expected changes: %v, but got: %v", expected, record.Changes())
+	}
+
+	if err := record.RemoveAttachments("Pictures", "att2", "https://example.com/3.png"); err != nil {
+		t.Fatalf("must be no error, but was: %v", err)
+	}
+	expected = map[string]any{"Pictures": []any{map[string]any{"id": "att1"}}}
+	if !reflect.DeepEqual(record.Changes(), expected) {
+		t.Errorf("expected changes: %v, but got: %v", expected, record.Changes())
+	}
+
+	if err := record.AppendAttachments("Name"); !errors.Is(err, ErrNotAttachments) {
+		t.Errorf("should be ErrNotAttachments, but was: %v", err)
+	}
+}
+
+func TestTable_AppendAttachments(t *testing.T) {
+	var requests []*Records
+	patchServer := mockPatchServer(t, &requests)
+	getServer := mockResponse("get_record_with_attachments.json")
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			getServer.Config.Handler.ServeHTTP(rw, r)
+			return
+		}
+		patchServer.Config.Handler.ServeHTTP(rw, r)
+	}))
+	defer server.Close()
+
+	table := testTable()
+	table.client.baseURL = server.URL
+
+	_, err := table.AppendAttachments("recnTq6CsvFM6vX2m", "Pictures", FieldAttachmentDetails{URL: "https://example.com/3.png"})
+	if err != nil {
+		t.Fatalf("must be no error, but was: %v", err)
+	}
+	_, err = table.RemoveAttachments("recnTq6CsvFM6vX2m", "Pictures", "att1")
+	if err != nil {
+		t.Fatalf("must be no error, but was: %v", err)
+	}
+	_, err = table.ReorderAttachments("recnTq6CsvFM6vX2m", "Pictures", "att2")
+	if err != nil {
+		t.Fatalf("must be no error, but was: %v", err)
+	}
+	expected := []map[string]any{
+		{"Pictures": []any{
+			map[string]any{"id": "att1"},
+			map[string]any{"id": "att2"},
+			map[string]any{"url": "https://example.com/3.png"},
+		}},
+		{"Pictures": []any{map[string]any{"id": "att2"}}},
+		{"Pictures": []any{map[string]any{"id": "att2"}, map[string]any{"id": "att1"}}},
+	}
+	if len(requests) != len(expected) {
+		t.Fatalf("expected %d requests, but was: %v", len(expected), requests)
+	}
+	for i, request := range requests {
+		if !reflect.DeepEqual(request.Records[0].Fields, expected[i]) {
+			t.Errorf("expected: %v\nbut got: %v", expected[i], request.Records[0].Fields)
+		}
+	}
+
+	table.client.baseURL = mockErrorResponse(404).URL
+	_, err = table.AppendAttachments("recnTq6CsvFM6vX2m", "Pictures")
+	if err == nil {
+		t.Errorf("there should be an err, but was nil")
+	}
+}
@@ -1,6 +1,31 @@
 package airtable
 
-import "context"
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// maxUploadAttachmentSize Airtable limit of file size uploaded with uploadAttachment.
+const maxUploadAttachmentSize = 5 * 1024 * 1024
+
+// AttachmentTooLargeError returned before upload when the file exceeds
+// the 5 MB uploadAttachment limit.
+type AttachmentTooLargeError struct {
+	FileName string
+	// Size in bytes, at least Limit + 1 when read from a stream.
+	Size  int64
+	Limit int64
+}
+
+func (e *AttachmentTooLargeError) Error() string {
+	return fmt.Sprintf("attachment %q is too large: %d bytes, limit %d bytes", e.FileName, e.Size, e.Limit)
+}
 
 type Attachment struct {
 	ContentType string `json:"contentType"`
@@ -29,6 +54,23 @@ type FieldAttachmentDetails struct {
 	Size int `json:"size"`
 	// Content-Type value
 	Type string `json:"type"`
+	// Image dimensions in pixels
+	Width  int `json:"width,omitempty"`
+	Height int `json:"height,omitempty"`
+	// Thumbnails of images and documents
+	Thumbnails *AttachmentThumbnails `json:"thumbnails,omitempty"`
+}
+
+type AttachmentThumbnails struct {
+	Small *AttachmentThumbnail `json:"small,omitempty"`
+	Large *AttachmentThumbnail `json:"large,omitempty"`
+	Full  *AttachmentThumbnail `json:"full,omitempty"`
+}
+
+type AttachmentThumbnail struct {
+	URL    string `json:"url"`
+	Width  int    `json:"width"`
+	Height int    `json:"height"`
 }
 
 func (t *Table) UploadAttachment(recordID string, attachmentFieldIdOrName string, data Attachment) (*FieldAttachments, error) {
@@ -48,3 +90,69 @@ func (t *Table) UploadAttachmentContext(ctx context.Context, recordID string, at
 
 	return result, nil
 }
+
+// UploadAttachmentFromReader uploads file content from reader.
+// Content type is detected by the file name extension or the content.
+func (t *Table) UploadAttachmentFromReader(recordID string, attachmentFieldIdOrName string, fileName string, r io.Reader) (*FieldAttachments, error) {
+	return t.UploadAttachmentFromReaderContext(context.Background(), recordID, attachmentFieldIdOrName, fileName, r)
+}
+
+// UploadAttachmentFromReaderContext uploads file content from reader
+// with custom context.
+func (t *Table) UploadAttachmentFromReaderContext(ctx context.Context, recordID string, attachmentFieldIdOrName string, fileName string, r io.Reader) (*FieldAttachments, error) {
+	content, err := io.ReadAll(io.LimitReader(r, maxUploadAttachmentSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("cannot read attachment %q: %w", fileName, err)
+	}
+	if len(content) > maxUploadAttachmentSize {
+		return nil, &AttachmentTooLargeError{
+			FileName: fileName,
+			Size:     int64(len(content)),
+			Limit:    maxUploadAttachmentSize,
+		}
+	}
+
+	data := Attachment{
+		ContentType: detectContentType(fileName, content),
+		File:        base64.StdEncoding.EncodeToString(content),
+		FileName:    fileName,
+	}
+
+	return t.UploadAttachmentContext(ctx, recordID, attachmentFieldIdOrName, data)
+}
+
+// UploadAttachmentFromFile uploads local file.
+func (t *Table) UploadAttachmentFromFile(recordID string, attachmentFieldIdOrName string, path string) (*FieldAttachments, error) {
+	return t.UploadAttachmentFromFileContext(context.Background(), recordID, attachmentFieldIdOrName, path)
+}
+
+// UploadAttachmentFromFileContext uploads local file
+// with custom context.
+func (t *Table) UploadAttachmentFromFileContext(ctx context.Context, recordID string, attachmentFieldIdOrName string, path string) (*FieldAttachments, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open attachment: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("cannot open attachment: %w", err)
+	}
+	if info.Size() > maxUploadAttachmentSize {
+		return nil, &AttachmentTooLargeError{
+			FileName: info.Name(),
+			Size:     info.Size(),
+			Limit:    maxUploadAttachmentSize,
+		}
+	}
+
+	return t.UploadAttachmentFromReaderContext(ctx, recordID, attachmentFieldIdOrName, filepath.Base(path), file)
+}
+
+func detectContentType(fileName string, content []byte) string {
+	if contentType := mime.TypeByExtension(filepath.Ext(fileName)); contentType != "" {
+		return contentType
+	}
+	return http.DetectContentType(content[:min(len(content), 512)])
+}
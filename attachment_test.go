@@ -1,8 +1,16 @@
 package airtable
 
 import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
 	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -45,3 +53,65 @@ func TestTable_UploadAttachment(t *testing.T) {
 		t.Errorf("should be an http error, but was not: %v", err)
 	}
 }
+
+func TestTable_UploadAttachmentFromReader(t *testing.T) {
+	mockData, err := os.ReadFile("testdata/upload_attachment.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got Attachment
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		requests++
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		_, _ = rw.Write(mockData)
+	}))
+	defer server.Close()
+
+	table := testTable()
+	table.client.uploadAttachmentBaseURL = server.URL
+
+	png := []byte("\x89PNG\r\n\x1a\n0000")
+	result, err := table.UploadAttachmentFromReader("recnTq6CsvFM6vX2m", "Attachments", "test", bytes.NewReader(png))
+	if err != nil {
+		t.Fatalf("must be no error, but: %v", err)
+	}
+	if result.Attachments["Attachments"][0].Id != "att1" {
+		t.Errorf("unexpected result: %v", result)
+	}
+	expected := Attachment{
+		ContentType: "image/png",
+		File:        base64.StdEncoding.EncodeToString(png),
+		FileName:    "test",
+	}
+	if got != expected {
+		t.Errorf("expected: %#v\nbut got: %#v", expected, got)
+	}
+
+	path := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(path, []byte("notes"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	_, err = table.UploadAttachmentFromFile("recnTq6CsvFM6vX2m", "Attachments", path)
+	if err != nil {
+		t.Fatalf("must be no error, but: %v", err)
+	}
+	if got.FileName != "notes.txt" || !strings.HasPrefix(got.ContentType, "text/plain") {
+		t.Errorf("unexpected attachment: %#v", got)
+	}
+
+	large := bytes.NewReader(make([]byte, maxUploadAttachmentSize+10))
+	_, err = table.UploadAttachmentFromReader("recnTq6CsvFM6vX2m", "Attachments", "large.bin", large)
+	var tooLarge *AttachmentTooLargeError
+	if !errors.As(err, &tooLarge) || tooLarge.FileName != "large.bin" {
+		t.Errorf("should be AttachmentTooLargeError, but was: %v", err)
+	}
+	if requests != 2 {
+		t.Errorf("too large attachment should not be sent, but requests were: %d", requests)
+	}
+
+	_, err = table.UploadAttachmentFromFile("recnTq6CsvFM6vX2m", "Attachments", filepath.Join(t.TempDir(), "missing"))
+	if err == nil {
+		t.Errorf("there should be an err, but was nil")
+	}
+}
@@ -0,0 +1,225 @@
+// Copyright © 2020 Mike Berezin
+//
+// Use of this source code is governed by an MIT license.
+// Details in the LICENSE file.
+
+// Package backup snapshots an Airtable base to a directory
+// and restores it into an empty base.
+//
+// The backup directory has the layout:
+//
+//	manifest.json        format version, base ID and tables
+//	schema.json          base schema from GetBaseSchema
+//	tables/<table>.json  JSON array of records of the table
+//	attachments/         attachment files, see airtable.AttachmentDownloader
+//	restore.jsonl        progress of an unfinished restore
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"time"
+
+	"github.com/mehanizm/airtable"
+)
+
+// Version of the backup format.
+const Version = 1
+
+const (
+	manifestFile   = "manifest.json"
+	schemaFile     = "schema.json"
+	tablesDir      = "tables"
+	attachmentsDir = "attachments"
+
+	// attachmentsBatch records to collect before downloading their attachments.
+	attachmentsBatch = 100
+)
+
+var (
+	// ErrBackupExists returned when the directory already has a backup.
+	ErrBackupExists = errors.New("backup already exists in the directory")
+	// ErrUnsupportedVersion returned for backups of newer format versions.
+	ErrUnsupportedVersion = errors.New("unsupported backup version")
+)
+
+// Manifest describes the backup.
+type Manifest struct {
+	Version     int       `json:"version"`
+	BaseID      string    `json:"baseId"`
+	CreatedTime time.Time `json:"createdTime"`
+	// Attachments reports whether attachment files are in the backup.
+	Attachments bool             `json:"attachments"`
+	Tables      []*ManifestTable `json:"tables"`
+}
+
+// ManifestTable backed up table.
+type ManifestTable struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	File    string `json:"file"`
+	Records int    `json:"records"`
+	// MissingAttachments IDs of attachments failed to download.
+	MissingAttachments []string `json:"missingAttachments,omitempty"`
+}
+
+// Options of the backup.
+type Options struct {
+	// Attachments downloads attachment files along with records.
+	Attachments bool
+	// Tables names or IDs to back up, all tables if empty.
+	Tables []string
+}
+
+// Backup writes schema and all records of the base to dir.
+// Attachments failed to download are listed in the manifest
+// and do not fail the backup.
+func Backup(ctx context.Context, client *airtable.Client, baseID, dir string, opts Options) (*Manifest, error) {
+	if _, err := os.Stat(filepath.Join(dir, manifestFile)); err == nil {
+		return nil, fmt.Errorf("%w: %s", ErrBackupExists, dir)
+	}
+	if err := os.MkdirAll(filepath.Join(dir, tablesDir), 0o755); err != nil {
+		return nil, err
+	}
+
+	schema, err := client.GetBaseSchema(baseID).GetTablesContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get base schema: %w", err)
+	}
+	for _, name := range opts.Tables {
+		if schema.Table(name) == nil {
+			return nil, fmt.Errorf("%w: %q", airtable.ErrTableNotFound, name)
+		}
+	}
+	if err := writeJSON(filepath.Join(dir, schemaFile), schema); err != nil {
+		return nil, err
+	}
+
+	manifest := &Manifest{
+		Version:     Version,
+		BaseID:      baseID,
+		CreatedTime: time.Now().UTC(),
+		Attachments: opts.Attachments,
+	}
+	var downloader *airtable.AttachmentDownloader
+	if opts.Attachments {
+		downloader = client.NewAttachmentDownloader(filepath.Join(dir, attachmentsDir))
+	}
+
+	for _, table := range schema.Tables {
+		if len(opts.Tables) > 0 && !slices.Contains(opts.Tables, table.ID) && !slices.Contains(opts.Tables, table.Name) {
+			continue
+		}
+		entry := &ManifestTable{
+			ID:   table.ID,
+			Name: table.Name,
+			File: filepath.ToSlash(filepath.Join(tablesDir, table.ID+".json")),
+		}
+		if err := backupTable(ctx, client.GetTable(baseID, table.ID), filepath.Join(dir, entry.File), entry, downloader); err != nil {
+			return nil, fmt.Errorf("table %q: %w", table.Name, err)
+		}
+		manifest.Tables = append(manifest.Tables, entry)
+	}
+
+	// manifest is written last, so an interrupted backup has none
+	if err := writeJSON(filepath.Join(dir, manifestFile), manifest); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+// backupTable streams records of the table to JSON array in the file.
+func backupTable(ctx context.Context, table *airtable.Table, path string, entry *ManifestTable, downloader *airtable.AttachmentDownloader) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var pending []*airtable.Record
+	download := func() error {
+		if downloader == nil || len(pending) == 0 {
+			return nil
+		}
+		results, err := downloader.DownloadRecords(ctx, pending)
+		if err != nil {
+			return err
+		}
+		for _, result := range results {
+			if result.Err != nil && result.Thumbnail == "" {
+				entry.MissingAttachments = append(entry.MissingAttachments, result.AttachmentID)
+			}
+		}
+		pending = pending[:0]
+		return nil
+	}
+
+	if _, err := f.WriteString("["); err != nil {
+		return err
+	}
+	enc := json.NewEncoder(f)
+	for record, err := range table.GetRecords().Iterate(ctx) {
+		if err != nil {
+			return err
+		}
+		if entry.Records > 0 {
+			if _, err := f.WriteString(","); err != nil {
+				return err
+			}
+		}
+		if err := enc.Encode(record); err != nil {
+			return err
+		}
+		entry.Records++
+
+		pending = append(pending, record)
+		if len(pending) == attachmentsBatch {
+			if err := download(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := download(); err != nil {
+		return err
+	}
+	if _, err := f.WriteString("]\n"); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// ReadManifest reads manifest of the backup in dir.
+func ReadManifest(dir string) (*Manifest, error) {
+	manifest := new(Manifest)
+	if err := readJSON(filepath.Join(dir, manifestFile), manifest); err != nil {
+		return nil, err
+	}
+	if manifest.Version > Version {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedVersion, manifest.Version)
+	}
+	return manifest, nil
+}
+
+func writeJSON(path string, v any) error {
+	b, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, b, 0o644)
+}
+
+func readJSON(path string, v any) error {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(b, v); err != nil {
+		return fmt.Errorf("cannot decode %s: %w", filepath.Base(path), err)
+	}
+	return nil
+}
@@ -0,0 +1,442 @@
+// Copyright © 2020 Mike Berezin
+//
+// Use of this source code is governed by an MIT license.
+// Details in the LICENSE file.
+
+package backup
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/mehanizm/airtable"
+	"github.com/mehanizm/airtable/internal/airtabletest"
+)
+
+// fakeTable table of the fake server.
+type fakeTable struct {
+	schema  *airtable.TableSchema
+	records []*airtable.Record
+}
+
+// fakeAirtable in-memory Airtable serving bases by ID: schema, records,
+// tables and fields creation, attachment uploads and attachment files.
+type fakeAirtable struct {
+	t      *testing.T
+	mu     sync.Mutex
+	url    string
+	bases  map[string][]*fakeTable
+	nextID int
+	files  map[string]string
+	// fail request with server error if set and returns true
+	fail func(r *http.Request) bool
+}
+
+func newFakeAirtable(t *testing.T) (*fakeAirtable, *airtable.Client) {
+	fake := &fakeAirtable{t: t, bases: map[string][]*fakeTable{}, files: map[string]string{}}
+	client, url := airtabletest.NewClient(t, fake)
+	fake.url = url
+	return fake, client
+}
+
+func (f *fakeAirtable) id(prefix string) string {
+	f.nextID++
+	return prefix + strconv.Itoa(f.nextID)
+}
+
+func (f *fakeAirtable) table(base, nameOrID string) *fakeTable {
+	for _, table := range f.bases[base] {
+		if table.schema.ID == nameOrID || table.schema.Name == nameOrID {
+			return table
+		}
+	}
+	return nil
+}
+
+func (f *fakeAirtable) recordTable(base, recordID string) *fakeTable {
+	for _, table := range f.bases[base] {
+		for _, record := range table.records {
+			if record.ID == recordID {
+				return table
+			}
+		}
+	}
+	return nil
+}
+
+func (f *fakeAirtable) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.fail != nil && f.fail(r) {
+		http.Error(w, "An error occurred", http.StatusInternalServerError)
+		return
+	}
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	var body map[string]any
+	if r.Body != nil {
+		_ = json.NewDecoder(r.Body).Decode(&body)
+	}
+	reply := func(v any) { airtabletest.Reply(f.t, w, v) }
+	decode := func(v any, target any) {
+		b, _ := json.Marshal(v)
+		if err := json.Unmarshal(b, target); err != nil {
+			f.t.Error(err)
+		}
+	}
+
+	switch {
+	case parts[0] == "files":
+		_, _ = w.Write([]byte(f.files[parts[1]]))
+
+	case parts[0] == "meta" && len(parts) == 4 && r.Method == http.MethodGet:
+		tables := &airtable.Tables{Tables: []*airtable.TableSchema{}}
+		for _, table := range f.bases[parts[2]] {
+			tables.Tables = append(tables.Tables, table.schema)
+		}
+		reply(tables)
+
+	case parts[0] == "meta" && len(parts) == 4 && r.Method == http.MethodPost:
+		schema := new(airtable.TableSchema)
+		decode(body, schema)
+		schema.ID = f.id("tbl")
+		for _, field := range schema.Fields {
+			field.ID = f.id("fld")
+		}
+		schema.PrimaryFieldID = schema.Fields[0].ID
+		f.bases[parts[2]] = append(f.bases[parts[2]], &fakeTable{schema: schema})
+		reply(schema)
+
+	case parts[0] == "meta" && len(parts) == 6 && r.Method == http.MethodPost:
+		table := f.table(parts[2], parts[4])
+		field := new(airtable.Field)
+		decode(body, field)
+		field.ID = f.id("fld")
+		table.schema.Fields = append(table.schema.Fields, field)
+		if field.Type == "multipleRecordLinks" {
+			linked := f.table(parts[2], field.Options["linkedTableId"].(string))
+			inverse := &airtable.Field{
+				ID:      f.id("fld"),
+				Name:    table.schema.Name,
+				Type:    field.Type,
+				Options: map[string]any{"linkedTableId": table.schema.ID, "inverseLinkFieldId": field.ID},
+			}
+			linked.schema.Fields = append(linked.schema.Fields, inverse)
+			field.Options["inverseLinkFieldId"] = inverse.ID
+		}
+		reply(field)
+
+	case parts[0] == "meta" && len(parts) == 7 && r.Method == http.MethodPatch:
+		field := f.table(parts[2], parts[4]).schema.Field(parts[6])
+		field.Name = body["name"].(string)
+		reply(field)
+
+	case len(parts) == 4 && parts[3] == "uploadAttachment":
+		table := f.recordTable(parts[0], parts[1])
+		for _, record := range table.records {
+			if record.ID == parts[1] {
+				attachments, _ := record.Fields[parts[2]].([]any)
+				record.Fields[parts[2]] = append(attachments, map[string]any{
+					"id":       f.id("att"),
+					"url":      f.url + "/files/" + body["filename"].(string),
+					"filename": body["filename"],
+					"type":     body["contentType"],
+				})
+			}
+		}
+		reply(map[string]any{"id": parts[1]})
+
+	case len(parts) == 2 && r.Method == http.MethodGet:
+		// pages of 2 records
+		reply(airtabletest.Page(r.URL.Query(), f.table(parts[0], parts[1]).records, 2))
+
+	case len(parts) == 2 && r.Method == http.MethodPost:
+		table := f.table(parts[0], parts[1])
+		records := new(airtable.Records)
+		decode(body, records)
+		for _, record := range records.Records {
+			record.ID = f.id("rec")
+			record.CreatedTime = "2024-01-01T00:00:00.000Z"
+			table.records = append(table.records, record)
+		}
+		reply(records)
+
+	case len(parts) == 2 && r.Method == http.MethodPatch:
+		table := f.table(parts[0], parts[1])
+		records := new(airtable.Records)
+		decode(body, records)
+		for _, update := range records.Records {
+			for _, record := range table.records {
+				if record.ID == update.ID {
+					for name, value := range update.Fields {
+						record.Fields[name] = value
+					}
+				}
+			}
+		}
+		reply(records)
+
+	default:
+		f.t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		http.NotFound(w, r)
+	}
+}
+
+// seed fills source base with Districts and Apartments linked to each other.
+func (f *fakeAirtable) seed() {
+	districts := &fakeTable{schema: &airtable.TableSchema{
+		ID: "tblDistricts", Name: "Districts", PrimaryFieldID: "fldDistrictName",
+		Fields: []*airtable.Field{
+			{ID: "fldDistrictName", Name: "Name", Type: "singleLineText"},
+			{ID: "fldDistrictApartments", Name: "Apartments", Type: "multipleRecordLinks",
+				Options: map[string]any{"linkedTableId": "tblApartments", "inverseLinkFieldId": "fldApartmentDistrict"}},
+		},
+	}}
+	apartments := &fakeTable{schema: &airtable.TableSchema{
+		ID: "tblApartments", Name: "Apartments", PrimaryFieldID: "fldApartmentID",
+		Fields: []*airtable.Field{
+			{ID: "fldApartmentName", Name: "Name", Type: "singleLineText"},
+			{ID: "fldApartmentID", Name: "ID", Type: "autoNumber"},
+			{ID: "fldApartmentType", Name: "Type", Type: "singleSelect",
+				Options: map[string]any{"choices": []any{map[string]any{"id": "sel1", "name": "Loft", "color": "blueLight2"}}}},
+			{ID: "fldApartmentDistrict", Name: "District", Type: "multipleRecordLinks",
+				Options: map[string]any{"linkedTableId": "tblDistricts", "inverseLinkFieldId": "fldDistrictApartments"}},
+			{ID: "fldApartmentPictures", Name: "Pictures", Type: "multipleAttachments",
+				Options: map[string]any{"isReversed": false}},
+			{ID: "fldApartmentAgent", Name: "Agent", Type: "singleCollaborator"},
+			{ID: "fldApartmentTitle", Name: "Title", Type: "formula", Options: map[string]any{"formula": "{Name}"}},
+		},
+	}}
+	districts.records = []*airtable.Record{
+		{ID: "recD1", Fields: map[string]any{"Name": "Center", "Apartments": []any{"recA1", "recA2"}}},
+		{ID: "recD2", Fields: map[string]any{"Name": "North", "Apartments": []any{"recA3"}}},
+	}
+	apartments.records = []*airtable.Record{
+		{ID: "recA1", Fields: map[string]any{
+			"Name": "Loft", "ID": float64(1), "Type": "Loft", "District": []any{"recD1"}, "Title": "Loft",
+			"Agent": map[string]any{"id": "usr1", "email": "agent@example.com", "name": "Agent"},
+			"Pictures": []any{map[string]any{
+				"id": "attA1", "url": f.url + "/files/loft.png", "filename": "loft.png", "size": float64(len("loft picture")),
+			}},
+		}},
+		{ID: "recA2", Fields: map[string]any{"Name": "Studio", "ID": float64(2), "District": []any{"recD1"}}},
+		{ID: "recA3", Fields: map[string]any{"Name": "House", "ID": float64(3), "District": []any{"recD2", "recDeleted"}}},
+	}
+	f.files["loft.png"] = "loft picture"
+	f.bases["appSource"] = []*fakeTable{apartments, districts}
+}
+
+func TestBackupRestore(t *testing.T) {
+	fake, client := newFakeAirtable(t)
+	fake.seed()
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	manifest, err := Backup(ctx, client, "appSource", dir, Options{Attachments: true})
+	if err != nil {
+		t.Fatalf("must be no error, but was: %v", err)
+	}
+	if manifest.Version != Version || len(manifest.Tables) != 2 || manifest.Tables[0].Records != 3 {
+		t.Errorf("unexpected manifest: %+v", manifest)
+	}
+	for _, name := range []string{"manifest.json", "schema.json", "tables/tblDistricts.json", "tables/tblApartments.json", "attachments/index.json"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("%s should be in the backup: %v", name, err)
+		}
+	}
+	if _, err := Backup(ctx, client, "appSource", dir, Options{}); !errors.Is(err, ErrBackupExists) {
+		t.Errorf("should be ErrBackupExists, but was: %v", err)
+	}
+
+	fake.files["loft.png"] = "expired"
+	result, err := Restore(ctx, client, dir, "appTarget")
+	if err != nil {
+		t.Fatalf("must be no error, but was: %v", err)
+	}
+
+	expectedSkipped := []string{
+		"Apartments.ID: autoNumber restored as singleLineText",
+		"Apartments.Title: formula can not be created",
+	}
+	if !reflect.DeepEqual(result.Skipped, expectedSkipped) {
+		t.Errorf("expected skipped %q, but was %q", expectedSkipped, result.Skipped)
+	}
+	if len(result.Records) != 5 || len(result.Tables) != 2 {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	districts := fake.table("appTarget", "Districts")
+	apartments := fake.table("appTarget", "Apartments")
+	if districts == nil || apartments == nil {
+		t.Fatalf("tables should be restored, but were: %v", fake.bases["appTarget"])
+	}
+	var fieldNames []string
+	for _, field := range apartments.schema.Fields {
+		fieldNames = append(fieldNames, field.Name+":"+field.Type)
+	}
+	expectedFields := []string{"ID:singleLineText", "Name:singleLineText", "Type:singleSelect", "Pictures:multipleAttachments", "Agent:singleCollaborator", "District:multipleRecordLinks"}
+	if !reflect.DeepEqual(fieldNames, expectedFields) {
+		t.Errorf("expected fields %q, but were %q", expectedFields, fieldNames)
+	}
+	if field := districts.schema.Field("Apartments"); field == nil || result.Fields["fldDistrictApartments"] != field.ID {
+		t.Errorf("inverse link field should be renamed and mapped, but was: %v", districts.schema.Fields)
+	}
+	if choice := apartments.schema.Field("Type").Options["choices"].([]any)[0].(map[string]any); choice["id"] != nil || choice["name"] != "Loft" {
+		t.Errorf("choice should be created without id, but was: %v", choice)
+	}
+	if options := apartments.schema.Field("Pictures").Options; options != nil {
+		t.Errorf("read only options should not be created, but were: %v", options)
+	}
+
+	loft := apartments.records[0]
+	expected := map[string]any{
+		"ID": "1", "Name": "Loft", "Type": "Loft",
+		"District": []any{result.Records["recD1"]},
+		"Agent":    map[string]any{"email": "agent@example.com"},
+	}
+	pictures := loft.Fields["Pictures"]
+	delete(loft.Fields, "Pictures")
+	if !reflect.DeepEqual(loft.Fields, expected) {
+		t.Errorf("expected %v, but was %v", expected, loft.Fields)
+	}
+	details, err := airtable.ToAttachments(pictures)
+	if err != nil || len(details) != 1 || details[0].FileName != "loft.png" {
+		t.Errorf("attachment should be uploaded from backup, but was: %v, %v", pictures, err)
+	}
+
+	house := apartments.records[2]
+	if !reflect.DeepEqual(house.Fields["District"], []any{result.Records["recD2"]}) {
+		t.Errorf("links to records not in backup should be dropped, but was: %v", house.Fields["District"])
+	}
+	if _, ok := districts.records[0].Fields["Apartments"]; ok {
+		t.Errorf("inverse links should not be written, but was: %v", districts.records[0].Fields)
+	}
+
+	_, err = Restore(ctx, client, dir, "appTarget")
+	if !errors.Is(err, ErrTableExists) {
+		t.Errorf("should be ErrTableExists, but was: %v", err)
+	}
+}
+
+func TestRestore_Resume(t *testing.T) {
+	fake, client := newFakeAirtable(t)
+	fake.seed()
+	dir := t.TempDir()
+	ctx := context.Background()
+
+	if _, err := Backup(ctx, client, "appSource", dir, Options{Attachments: true}); err != nil {
+		t.Fatalf("must be no error, but was: %v", err)
+	}
+
+	// records of the first table are inserted, of the second one fail
+	first := ""
+	fake.fail = func(r *http.Request) bool {
+		if r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/appTarget/") {
+			if first == "" {
+				first = r.URL.Path
+			}
+			return r.URL.Path != first
+		}
+		return false
+	}
+	if _, err := Restore(ctx, client, dir, "appTarget"); err == nil {
+		t.Fatal("must be error")
+	}
+	if _, err := os.Stat(filepath.Join(dir, restoreStateFile)); err != nil {
+		t.Fatalf("restore state should be saved: %v", err)
+	}
+	if _, err := Restore(ctx, client, dir, "appOther"); !errors.Is(err, ErrRestoreMismatch) {
+		t.Errorf("should be ErrRestoreMismatch, but was: %v", err)
+	}
+
+	fake.fail = nil
+	result, err := Restore(ctx, client, dir, "appTarget")
+	if err != nil {
+		t.Fatalf("must be no error, but was: %v", err)
+	}
+	if len(fake.bases["appTarget"]) != 2 {
+		t.Errorf("tables should not be created again, but were: %d", len(fake.bases["appTarget"]))
+	}
+	apartments := fake.table("appTarget", "Apartments")
+	if len(apartments.records) != 3 || len(fake.table("appTarget", "Districts").records) != 2 {
+		t.Errorf("records should not be inserted again, but were: %v", fake.bases["appTarget"])
+	}
+	if !reflect.DeepEqual(apartments.records[0].Fields["District"], []any{result.Records["recD1"]}) {
+		t.Errorf("links should be remapped, but was: %v", apartments.records[0].Fields["District"])
+	}
+	if pictures, _ := airtable.ToAttachments(apartments.records[0].Fields["Pictures"]); len(pictures) != 1 {
+		t.Errorf("attachment should be uploaded once, but was: %v", pictures)
+	}
+	if _, err := os.Stat(filepath.Join(dir, restoreStateFile)); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("restore state should be removed, but was: %v", err)
+	}
+}
+
+func TestBackup_Tables(t *testing.T) {
+	fake, client := newFakeAirtable(t)
+	fake.seed()
+	dir := t.TempDir()
+
+	manifest, err := Backup(context.Background(), client, "appSource", dir, Options{Tables: []string{"Apartments"}})
+	if err != nil {
+		t.Fatalf("must be no error, but was: %v", err)
+	}
+	if len(manifest.Tables) != 1 || manifest.Attachments {
+		t.Errorf("unexpected manifest: %+v", manifest)
+	}
+
+	var records []*airtable.Record
+	b, err := os.ReadFile(filepath.Join(dir, manifest.Tables[0].File))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(b, &records); err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, record := range records {
+		ids = append(ids, record.ID)
+	}
+	sort.Strings(ids)
+	if !slices.Equal(ids, []string{"recA1", "recA2", "recA3"}) {
+		t.Errorf("unexpected records: %v", ids)
+	}
+
+	result, err := Restore(context.Background(), client, dir, "appTarget")
+	if err != nil {
+		t.Fatalf("must be no error, but was: %v", err)
+	}
+	if !slices.Contains(result.Skipped, "Apartments.District: linked table is not restored") {
+		t.Errorf("link to not restored table should be skipped, but was: %q", result.Skipped)
+	}
+	pictures, _ := airtable.ToAttachments(fake.table("appTarget", "Apartments").records[0].Fields["Pictures"])
+	if len(pictures) != 1 || pictures[0].URL != fake.url+"/files/loft.png" {
+		t.Errorf("attachment should be attached by URL without backup files, but was: %v", pictures)
+	}
+
+	if _, err := Backup(context.Background(), client, "appSource", t.TempDir(), Options{Tables: []string{"Unknown"}}); !errors.Is(err, airtable.ErrTableNotFound) {
+		t.Errorf("should be ErrTableNotFound, but was: %v", err)
+	}
+}
+
+func TestReadManifest(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, manifestFile), []byte(fmt.Sprintf(`{"version": %d}`, Version+1)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadManifest(dir); !errors.Is(err, ErrUnsupportedVersion) {
+		t.Errorf("should be ErrUnsupportedVersion, but was: %v", err)
+	}
+}
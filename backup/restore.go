@@ -0,0 +1,668 @@
+// Copyright © 2020 Mike Berezin
+//
+// Use of this source code is governed by an MIT license.
+// Details in the LICENSE file.
+
+package backup
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+
+	"github.com/mehanizm/airtable"
+	"github.com/mehanizm/airtable/exporter"
+)
+
+// restoreStateFile steps of an unfinished restore in the backup directory.
+const restoreStateFile = "restore.jsonl"
+
+var (
+	// ErrTableExists returned when the target base already has a table
+	// with the name of a backed up table.
+	ErrTableExists = errors.New("table already exists in target base")
+	// ErrRestoreMismatch returned when the backup directory has
+	// an unfinished restore into another base.
+	ErrRestoreMismatch = errors.New("backup has unfinished restore into another base")
+)
+
+// creatableTypes field types which can be created with the API.
+var creatableTypes = map[string]bool{
+	"singleLineText":        true,
+	"multilineText":         true,
+	"richText":              true,
+	"email":                 true,
+	"url":                   true,
+	"phoneNumber":           true,
+	"number":                true,
+	"percent":               true,
+	"currency":              true,
+	"rating":                true,
+	"duration":              true,
+	"checkbox":              true,
+	"singleSelect":          true,
+	"multipleSelects":       true,
+	"singleCollaborator":    true,
+	"multipleCollaborators": true,
+	"multipleRecordLinks":   true,
+	"multipleAttachments":   true,
+	"date":                  true,
+	"dateTime":              true,
+	"barcode":               true,
+}
+
+// primaryTypes field types allowed for the primary field.
+var primaryTypes = map[string]bool{
+	"singleLineText": true,
+	"multilineText":  true,
+	"email":          true,
+	"url":            true,
+	"phoneNumber":    true,
+	"number":         true,
+	"percent":        true,
+	"currency":       true,
+	"duration":       true,
+	"date":           true,
+	"dateTime":       true,
+}
+
+// RestoreResult maps IDs of the backup to IDs in the restored base.
+type RestoreResult struct {
+	Tables  map[string]string
+	Fields  map[string]string
+	Records map[string]string
+	// Skipped fields not restored or restored as text, as "Table.Field: reason".
+	Skipped []string
+}
+
+// fieldPlan how values of the field are restored.
+type fieldPlan int
+
+const (
+	planSkip fieldPlan = iota
+	planWrite
+	planText
+	planLink
+	planAttachments
+)
+
+// writeOptions options accepted when the field is created by field type,
+// other options of the schema are read only. Types not listed are created without options.
+var writeOptions = map[string][]string{
+	"number":   {"precision"},
+	"percent":  {"precision"},
+	"currency": {"precision", "symbol"},
+	"rating":   {"max", "icon", "color"},
+	"duration": {"durationFormat"},
+	"checkbox": {"icon", "color"},
+	"date":     {"dateFormat"},
+	"dateTime": {"dateFormat", "timeFormat", "timeZone"},
+}
+
+// restoreStep completed step of the restore, a line of the restore state file.
+type restoreStep struct {
+	BaseID  string            `json:"baseId,omitempty"`
+	Tables  map[string]string `json:"tables,omitempty"`
+	Fields  map[string]string `json:"fields,omitempty"`
+	Records map[string]string `json:"records,omitempty"`
+	// Link backup ID of the link field created from its table.
+	Link string `json:"link,omitempty"`
+	// Attachment backup ID of the uploaded attachment.
+	Attachment string `json:"attachment,omitempty"`
+}
+
+type restorer struct {
+	client     *airtable.Client
+	baseID     string
+	manifest   *Manifest
+	schema     *airtable.Tables
+	downloader *airtable.AttachmentDownloader
+	result     *RestoreResult
+	// plans of fields by backup table ID and field name
+	plans map[string]map[string]fieldPlan
+	// links backup IDs of created link fields
+	links map[string]bool
+	// uploaded backup IDs of uploaded attachments
+	uploaded map[string]bool
+	state    *os.File
+}
+
+// pendingLinks link values of the restored record with backup record IDs.
+type pendingLinks struct {
+	recordID string
+	links    map[string][]string
+}
+
+// pendingAttachment attachment file to upload to the restored record.
+type pendingAttachment struct {
+	recordID     string
+	field        string
+	attachmentID string
+	fileName     string
+}
+
+// Restore creates tables and fields of the backup in dir in the empty base
+// and inserts records. Record IDs in linked record fields are remapped
+// to the restored records. Computed fields (formulas, rollups, lookups, etc.)
+// can not be created with the API and are skipped, a computed primary field
+// is restored as text. Attachments are uploaded from the backup files if present,
+// or attached by URL which works only while URLs of the backup are not expired.
+//
+// The restore is not atomic. Created tables, fields, records and uploaded attachments
+// are saved to restore.jsonl in dir, and a failed restore is resumed by running it again
+// into the same base, link values are written again. The file is removed when
+// the restore is finished. A table created right before the failure may be not saved,
+// then the retry returns ErrTableExists and the table must be deleted from the base.
+// Records are restored at least once: a batch is saved after it is created,
+// so records of the batch created before a crash or cancel are created again on resume.
+func Restore(ctx context.Context, client *airtable.Client, dir, baseID string) (*RestoreResult, error) {
+	manifest, err := ReadManifest(dir)
+	if err != nil {
+		return nil, err
+	}
+	schema := new(airtable.Tables)
+	if err := readJSON(filepath.Join(dir, schemaFile), schema); err != nil {
+		return nil, err
+	}
+
+	r := &restorer{
+		client:   client,
+		baseID:   baseID,
+		manifest: manifest,
+		schema:   schema,
+		result: &RestoreResult{
+			Tables:  map[string]string{},
+			Fields:  map[string]string{},
+			Records: map[string]string{},
+		},
+		plans:    map[string]map[string]fieldPlan{},
+		links:    map[string]bool{},
+		uploaded: map[string]bool{},
+	}
+	statePath := filepath.Join(dir, restoreStateFile)
+	saved, err := r.loadState(statePath)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := client.GetBaseSchema(baseID).GetTablesContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get target base schema: %w", err)
+	}
+	for _, table := range manifest.Tables {
+		if _, ok := r.result.Tables[table.ID]; !ok && existing.Table(table.Name) != nil {
+			return nil, fmt.Errorf("%w: %q", ErrTableExists, table.Name)
+		}
+		if schema.Table(table.ID) == nil {
+			return nil, fmt.Errorf("%w: %q", airtable.ErrTableNotFound, table.Name)
+		}
+	}
+
+	if err := r.openState(statePath, saved); err != nil {
+		return nil, err
+	}
+	defer r.state.Close()
+	if manifest.Attachments {
+		r.downloader = client.NewAttachmentDownloader(filepath.Join(dir, attachmentsDir))
+	}
+
+	for _, table := range manifest.Tables {
+		if err := r.createTable(ctx, schema.Table(table.ID)); err != nil {
+			return r.result, fmt.Errorf("table %q: %w", table.Name, err)
+		}
+	}
+	for _, table := range manifest.Tables {
+		if err := r.createLinks(ctx, schema.Table(table.ID)); err != nil {
+			return r.result, fmt.Errorf("table %q: %w", table.Name, err)
+		}
+	}
+
+	links := map[string][]pendingLinks{}
+	attachments := map[string][]pendingAttachment{}
+	for _, table := range manifest.Tables {
+		var records []*airtable.Record
+		if err := readJSON(filepath.Join(dir, filepath.FromSlash(table.File)), &records); err != nil {
+			return r.result, err
+		}
+		if err := r.insertRecords(ctx, table.ID, records); err != nil {
+			return r.result, fmt.Errorf("table %q: %w", table.Name, err)
+		}
+		links[table.ID], attachments[table.ID] = r.pending(table.ID, records)
+	}
+	for _, table := range manifest.Tables {
+		if err := r.updateLinks(ctx, table.ID, links[table.ID]); err != nil {
+			return r.result, fmt.Errorf("table %q: %w", table.Name, err)
+		}
+		if err := r.uploadAttachments(ctx, table.ID, attachments[table.ID]); err != nil {
+			return r.result, fmt.Errorf("table %q: %w", table.Name, err)
+		}
+	}
+
+	if err := r.state.Close(); err != nil {
+		return r.result, err
+	}
+	if err := os.Remove(statePath); err != nil {
+		return r.result, err
+	}
+	return r.result, nil
+}
+
+// loadState applies steps of the unfinished restore saved in the file
+// and returns the length of the saved steps.
+func (r *restorer) loadState(path string) (int, error) {
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	saved := 0
+	for line := 1; saved < len(b); line++ {
+		end := bytes.IndexByte(b[saved:], '\n')
+		if end < 0 {
+			// torn write of the last step
+			break
+		}
+		step := new(restoreStep)
+		if err := json.Unmarshal(b[saved:saved+end], step); err != nil {
+			return 0, fmt.Errorf("corrupted restore state line %d: %w", line, err)
+		}
+		if step.BaseID != "" && step.BaseID != r.baseID {
+			return 0, fmt.Errorf("%w: %s", ErrRestoreMismatch, step.BaseID)
+		}
+		r.apply(step)
+		saved += end + 1
+	}
+	return saved, nil
+}
+
+// openState opens the state file to save steps after the saved ones.
+func (r *restorer) openState(path string, saved int) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	if err := file.Truncate(int64(saved)); err != nil {
+		file.Close()
+		return err
+	}
+	r.state = file
+	if saved == 0 {
+		return r.save(&restoreStep{BaseID: r.baseID})
+	}
+	return nil
+}
+
+// save appends the completed step to the state file and applies it.
+func (r *restorer) save(step *restoreStep) error {
+	b, err := json.Marshal(step)
+	if err != nil {
+		return err
+	}
+	if _, err := r.state.Write(append(b, '\n')); err != nil {
+		return fmt.Errorf("cannot save restore state: %w", err)
+	}
+	if err := r.state.Sync(); err != nil {
+		return fmt.Errorf("cannot save restore state: %w", err)
+	}
+	r.apply(step)
+	return nil
+}
+
+func (r *restorer) apply(step *restoreStep) {
+	maps.Copy(r.result.Tables, step.Tables)
+	maps.Copy(r.result.Fields, step.Fields)
+	maps.Copy(r.result.Records, step.Records)
+	if step.Link != "" {
+		r.links[step.Link] = true
+	}
+	if step.Attachment != "" {
+		r.uploaded[step.Attachment] = true
+	}
+}
+
+func (r *restorer) skip(table *airtable.TableSchema, field *airtable.Field, reason string) {
+	r.result.Skipped = append(r.result.Skipped, fmt.Sprintf("%s.%s: %s", table.Name, field.Name, reason))
+}
+
+// restored reports whether the backup table is restored.
+func (r *restorer) restored(tableID string) bool {
+	for _, table := range r.manifest.Tables {
+		if table.ID == tableID {
+			return true
+		}
+	}
+	return false
+}
+
+// createTable plans fields of the table and creates it with all fields except links,
+// created after all tables. Tables created by the interrupted restore are only planned.
+func (r *restorer) createTable(ctx context.Context, table *airtable.TableSchema) error {
+	plans := map[string]fieldPlan{}
+	r.plans[table.ID] = plans
+
+	create := &airtable.TableSchema{Name: table.Name, Description: table.Description}
+	for _, field := range table.Fields {
+		primary := field.ID == table.PrimaryFieldID
+		switch {
+		case primary && !primaryTypes[field.Type]:
+			create.Fields = append([]*airtable.Field{{Name: field.Name, Type: "singleLineText", Description: field.Description}}, create.Fields...)
+			plans[field.Name] = planText
+			r.skip(table, field, fmt.Sprintf("%s restored as singleLineText", field.Type))
+		case !creatableTypes[field.Type]:
+			plans[field.Name] = planSkip
+			r.skip(table, field, fmt.Sprintf("%s can not be created", field.Type))
+		case field.Type == "multipleRecordLinks":
+			linkedTableID, _ := field.Options["linkedTableId"].(string)
+			if !r.restored(linkedTableID) {
+				plans[field.Name] = planSkip
+				r.skip(table, field, "linked table is not restored")
+				continue
+			}
+			plans[field.Name] = planLink
+		default:
+			restored := &airtable.Field{
+				Name:        field.Name,
+				Type:        field.Type,
+				Description: field.Description,
+				Options:     createOptions(field),
+			}
+			if primary {
+				create.Fields = append([]*airtable.Field{restored}, create.Fields...)
+			} else {
+				create.Fields = append(create.Fields, restored)
+			}
+			plans[field.Name] = planWrite
+			if field.Type == "multipleAttachments" {
+				plans[field.Name] = planAttachments
+			}
+		}
+	}
+
+	if _, ok := r.result.Tables[table.ID]; ok {
+		return nil
+	}
+	created, err := r.client.GetBaseSchema(r.baseID).CreateTableContext(ctx, create)
+	if err != nil {
+		return err
+	}
+	step := &restoreStep{Tables: map[string]string{table.ID: created.ID}, Fields: map[string]string{}}
+	for _, field := range table.Fields {
+		if restored := created.Field(field.Name); restored != nil {
+			step.Fields[field.ID] = restored.ID
+		}
+	}
+	return r.save(step)
+}
+
+// createLinks creates link fields of the table and renames inverse fields
+// created by Airtable in the linked tables to their backup names.
+func (r *restorer) createLinks(ctx context.Context, table *airtable.TableSchema) error {
+	base := r.client.GetBaseSchema(r.baseID)
+	for _, field := range table.Fields {
+		if r.plans[table.ID][field.Name] != planLink {
+			continue
+		}
+		if r.links[field.ID] {
+			continue
+		}
+		if _, ok := r.result.Fields[field.ID]; ok {
+			// inverse of the link created from the other table
+			r.plans[table.ID][field.Name] = planSkip
+			continue
+		}
+
+		linkedTableID, _ := field.Options["linkedTableId"].(string)
+		created, err := base.CreateFieldContext(ctx, r.result.Tables[table.ID], &airtable.Field{
+			Name:        field.Name,
+			Type:        field.Type,
+			Description: field.Description,
+			Options:     map[string]any{"linkedTableId": r.result.Tables[linkedTableID]},
+		})
+		if err != nil {
+			return fmt.Errorf("field %q: %w", field.Name, err)
+		}
+		step := &restoreStep{Fields: map[string]string{field.ID: created.ID}, Link: field.ID}
+
+		inverseID, _ := field.Options["inverseLinkFieldId"].(string)
+		createdInverseID, _ := created.Options["inverseLinkFieldId"].(string)
+		if inverse := r.schema.Table(linkedTableID).Field(inverseID); inverse != nil && createdInverseID != "" {
+			if _, err := base.UpdateFieldContext(ctx, r.result.Tables[linkedTableID], createdInverseID, inverse.Name, inverse.Description); err != nil {
+				return fmt.Errorf("field %q: %w", inverse.Name, err)
+			}
+			step.Fields[inverse.ID] = createdInverseID
+		}
+		if err := r.save(step); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// insertRecords adds records without links and attachment files,
+// which are written after all records are restored.
+// Records inserted by the interrupted restore are skipped.
+func (r *restorer) insertRecords(ctx context.Context, tableID string, records []*airtable.Record) error {
+	table := r.client.GetTable(r.baseID, r.result.Tables[tableID])
+	plans := r.plans[tableID]
+
+	var missing []*airtable.Record
+	for _, record := range records {
+		if _, ok := r.result.Records[record.ID]; !ok {
+			missing = append(missing, record)
+		}
+	}
+	for start := 0; start < len(missing); start += airtable.MaxBatchSize {
+		batch := missing[start:min(start+airtable.MaxBatchSize, len(missing))]
+		request := &airtable.Records{Typecast: true}
+		for _, record := range batch {
+			request.Records = append(request.Records, &airtable.Record{Fields: r.fields(plans, record.Fields)})
+		}
+
+		response, err := table.AddRecordsContext(ctx, request)
+		if err != nil {
+			return err
+		}
+		if len(response.Records) != len(batch) {
+			return fmt.Errorf("created %d records of %d", len(response.Records), len(batch))
+		}
+
+		step := &restoreStep{Records: make(map[string]string, len(batch))}
+		for i, record := range batch {
+			step.Records[record.ID] = response.Records[i].ID
+		}
+		if err := r.save(step); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pending returns links and attachment files of the restored records to write.
+// Attachments uploaded by the interrupted restore are skipped.
+func (r *restorer) pending(tableID string, records []*airtable.Record) ([]pendingLinks, []pendingAttachment) {
+	plans := r.plans[tableID]
+
+	var links []pendingLinks
+	var attachments []pendingAttachment
+	for _, record := range records {
+		newID := r.result.Records[record.ID]
+		pending := pendingLinks{recordID: newID, links: map[string][]string{}}
+		for name, value := range record.Fields {
+			switch plans[name] {
+			case planLink:
+				if ids, err := airtable.ToLinkedIDs(value); err == nil && len(ids) > 0 {
+					pending.links[name] = ids
+				}
+			case planAttachments:
+				if r.downloader == nil {
+					continue
+				}
+				details, _ := airtable.ToAttachments(value)
+				for _, a := range details {
+					if _, ok := r.downloader.Index(a.Id); ok && !r.uploaded[a.Id] {
+						attachments = append(attachments, pendingAttachment{
+							recordID:     newID,
+							field:        name,
+							attachmentID: a.Id,
+							fileName:     a.FileName,
+						})
+					}
+				}
+			}
+		}
+		if len(pending.links) > 0 {
+			links = append(links, pending)
+		}
+	}
+	return links, attachments
+}
+
+// fields converts backup values to values to write.
+func (r *restorer) fields(plans map[string]fieldPlan, values map[string]any) map[string]any {
+	fields := make(map[string]any, len(values))
+	for name, value := range values {
+		switch plans[name] {
+		case planWrite:
+			fields[name] = writeValue(value)
+		case planText:
+			fields[name] = exporter.FormatValue(value)
+		case planAttachments:
+			// attachments with backup files are uploaded later,
+			// others are attached by URL
+			details, err := airtable.ToAttachments(value)
+			if err != nil {
+				continue
+			}
+			var byURL []airtable.FieldAttachmentDetails
+			for _, a := range details {
+				if r.downloader != nil {
+					if _, ok := r.downloader.Index(a.Id); ok {
+						continue
+					}
+				}
+				byURL = append(byURL, airtable.FieldAttachmentDetails{URL: a.URL, FileName: a.FileName})
+			}
+			if len(byURL) > 0 {
+				fields[name] = airtable.FromAttachments(byURL)
+			}
+		}
+	}
+	return fields
+}
+
+// writeValue drops read-only parts of the value:
+// collaborators are written by email or ID.
+func writeValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		if _, ok := v["email"]; ok {
+			if collaborator, err := airtable.ToCollaborator(v); err == nil {
+				if collaborator.Email != "" {
+					return map[string]any{"email": collaborator.Email}
+				}
+				return map[string]any{"id": collaborator.ID}
+			}
+		}
+	case []any:
+		values := make([]any, len(v))
+		for i, item := range v {
+			values[i] = writeValue(item)
+		}
+		return values
+	}
+	return value
+}
+
+// updateLinks writes link values with remapped record IDs.
+// Links to records which are not in the backup are dropped.
+func (r *restorer) updateLinks(ctx context.Context, tableID string, links []pendingLinks) error {
+	table := r.client.GetTable(r.baseID, r.result.Tables[tableID])
+	for start := 0; start < len(links); start += airtable.MaxBatchSize {
+		request := &airtable.Records{}
+		for _, pending := range links[start:min(start+airtable.MaxBatchSize, len(links))] {
+			fields := map[string]any{}
+			for name, ids := range pending.links {
+				var remapped []string
+				for _, id := range ids {
+					if newID, ok := r.result.Records[id]; ok {
+						remapped = append(remapped, newID)
+					}
+				}
+				fields[name] = remapped
+			}
+			request.Records = append(request.Records, &airtable.Record{ID: pending.recordID, Fields: fields})
+		}
+		if _, err := table.UpdateRecordsPartialContext(ctx, request); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// uploadAttachments uploads attachment files of the backup.
+// Files over the upload limit are skipped.
+func (r *restorer) uploadAttachments(ctx context.Context, tableID string, attachments []pendingAttachment) error {
+	table := r.client.GetTable(r.baseID, r.result.Tables[tableID])
+	for _, a := range attachments {
+		f, file, err := r.downloader.Open(a.attachmentID)
+		if err != nil {
+			return err
+		}
+		fileName := a.fileName
+		if fileName == "" {
+			fileName = file.FileName
+		}
+		_, err = table.UploadAttachmentFromReaderContext(ctx, a.recordID, a.field, fileName, f)
+		f.Close()
+		var tooLarge *airtable.AttachmentTooLargeError
+		if errors.As(err, &tooLarge) {
+			r.result.Skipped = append(r.result.Skipped, fmt.Sprintf("attachment %s: %v", a.attachmentID, err))
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("attachment %s: %w", a.attachmentID, err)
+		}
+		if err := r.save(&restoreStep{Attachment: a.attachmentID}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createOptions copies options of the field accepted when the field is created.
+func createOptions(field *airtable.Field) map[string]any {
+	if field.Type == "singleSelect" || field.Type == "multipleSelects" {
+		choices, _ := field.Options["choices"].([]any)
+		created := make([]any, 0, len(choices))
+		for _, choice := range choices {
+			c, ok := choice.(map[string]any)
+			if !ok {
+				continue
+			}
+			createdChoice := map[string]any{"name": c["name"]}
+			if color, ok := c["color"]; ok {
+				createdChoice["color"] = color
+			}
+			created = append(created, createdChoice)
+		}
+		return map[string]any{"choices": created}
+	}
+
+	var options map[string]any
+	for _, name := range writeOptions[field.Type] {
+		if value, ok := field.Options[name]; ok {
+			if options == nil {
+				options = map[string]any{}
+			}
+			options[name] = value
+		}
+	}
+	return options
+}
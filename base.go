@@ -2,9 +2,14 @@ package airtable
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/url"
 )
 
+// ErrTableNotFound returned when the table is not found in the base schema.
+var ErrTableNotFound = errors.New("table not found in base schema")
+
 // Base type of airtable base.
 type Base struct {
 	ID              string `json:"id"`
@@ -45,6 +50,26 @@ type Tables struct {
 	Tables []*TableSchema `json:"tables"`
 }
 
+// Table returns table schema by name or ID.
+func (t *Tables) Table(nameOrID string) *TableSchema {
+	for _, table := range t.Tables {
+		if table.ID == nameOrID || table.Name == nameOrID {
+			return table
+		}
+	}
+	return nil
+}
+
+// Field returns field schema by name or ID.
+func (t *TableSchema) Field(nameOrID string) *Field {
+	for _, field := range t.Fields {
+		if field.ID == nameOrID || field.Name == nameOrID {
+			return field
+		}
+	}
+	return nil
+}
+
 // GetBasesWithParams get bases with url values params
 // https://airtable.com/developers/web/api/list-bases
 func (at *Client) GetBasesWithParams(params url.Values) (*Bases, error) {
@@ -99,10 +124,33 @@ func (b *BaseConfig) GetTables() (*Tables, error) {
 func (b *BaseConfig) GetTablesContext(ctx context.Context) (*Tables, error) {
 	tables := new(Tables)
 
-	err := b.client.get(ctx, "meta/bases", b.dbId, "tables", nil, tables)
+	err := b.client.cached(ctx, b.dbId, schemaCacheKey(b.dbId), b.client.cacheTTL(cacheSchema), tables, func() error {
+		return b.client.get(ctx, "meta/bases", b.dbId, "tables", nil, tables)
+	})
 	if err != nil {
 		return nil, err
 	}
 
 	return tables, nil
 }
+
+// GetSchema get schema of the table from the base schema.
+func (t *Table) GetSchema() (*TableSchema, error) {
+	return t.GetSchemaContext(context.Background())
+}
+
+// GetSchemaContext get schema of the table from the base schema
+// with custom context.
+func (t *Table) GetSchemaContext(ctx context.Context) (*TableSchema, error) {
+	tables, err := t.client.GetBaseSchema(t.dbName).GetTablesContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	schema := tables.Table(t.tableName)
+	if schema == nil {
+		return nil, fmt.Errorf("%w: %q", ErrTableNotFound, t.tableName)
+	}
+
+	return schema, nil
+}
@@ -1,6 +1,7 @@
 package airtable
 
 import (
+	"errors"
 	"testing"
 )
 
@@ -17,9 +18,44 @@ func TestGetBases(t *testing.T) {
 		t.Errorf("there should be 2 tales, but was %v", len(result.Tables))
 	}
 
+	table := result.Table("Districts")
+	if table == nil || table.ID != "tblK6MZHez0ZvBChZ" || result.Table("tblK6MZHez0ZvBChZ") != table {
+		t.Errorf("table should be found by name and id, but was %v", table)
+	}
+	if field := table.Field("Apartments"); field == nil || table.Field(field.ID) != field {
+		t.Errorf("field should be found by name and id, but was %v", field)
+	}
+	if result.Table("Unknown") != nil || table.Field("Unknown") != nil {
+		t.Errorf("unknown table and field should be nil")
+	}
+
 	baseschema.client.baseURL = mockErrorResponse(400).URL
 	_, err = baseschema.Do()
 	if err == nil {
 		t.Errorf("there should be an err, but was nil")
 	}
 }
+
+func TestTable_GetSchema(t *testing.T) {
+	client := testClient()
+	client.baseURL = mockResponse("base_schema.json").URL
+
+	schema, err := client.GetTable("dbName", "Districts").GetSchema()
+	if err != nil {
+		t.Fatalf("there should not be an err, but was: %v", err)
+	}
+	if schema.ID != "tblK6MZHez0ZvBChZ" {
+		t.Errorf("unexpected schema: %v", schema)
+	}
+
+	_, err = client.GetTable("dbName", "Unknown").GetSchema()
+	if !errors.Is(err, ErrTableNotFound) {
+		t.Errorf("should be ErrTableNotFound, but was: %v", err)
+	}
+
+	client.baseURL = mockErrorResponse(400).URL
+	_, err = client.GetTable("dbName", "Districts").GetSchema()
+	if err == nil {
+		t.Errorf("there should be an err, but was nil")
+	}
+}
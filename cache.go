@@ -0,0 +1,268 @@
+// Copyright © 2020 Mike Berezin
+//
+// Use of this source code is governed by an MIT license.
+// Details in the LICENSE file.
+
+package airtable
+
+import (
+	"container/list"
+	"context"
+	"encoding/json"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const defaultCacheSize = 1000
+
+// CacheStore stores cached responses, implementations must be safe for concurrent use.
+type CacheStore interface {
+	// Get returns the value if it is not expired.
+	Get(key string) ([]byte, bool)
+	// Set stores the value for ttl.
+	Set(key string, value []byte, ttl time.Duration)
+	// DeletePrefix deletes values with keys starting with prefix.
+	DeletePrefix(prefix string)
+}
+
+// CacheOptions options of the client cache.
+// Zero TTL disables caching of the responses of that kind.
+type CacheOptions struct {
+	// Store of the responses, in-memory LRU of 1000 responses by default.
+	Store CacheStore
+	// RecordTTL of GetRecordContext and GetRecordWithParamsContext responses.
+	RecordTTL time.Duration
+	// RecordsTTL of GetRecordsWithParamsContext responses, pages of GetRecords.
+	// Offsets of cached pages may expire before the TTL.
+	RecordsTTL time.Duration
+	// SchemaTTL of GetTablesContext responses.
+	SchemaTTL time.Duration
+}
+
+// SetCache enables read-through cache of records and schema, nil disables it.
+// Writes of the client to records drop cached records of them and cached
+// record lists of the base, schema writes drop everything cached for the base.
+// Changes made by others are seen after the TTL.
+// SaveIfUnchanged, attachment field changes and Watch always read the current records.
+func (at *Client) SetCache(opts *CacheOptions) {
+	if opts == nil {
+		at.cache = nil
+		return
+	}
+	c := &clientCache{opts: *opts, generations: map[string]uint64{}}
+	if c.opts.Store == nil {
+		c.opts.Store = NewLRUCache(defaultCacheSize)
+	}
+	at.cache = c
+}
+
+// clientCache cache of the client.
+type clientCache struct {
+	opts CacheOptions
+
+	mu sync.Mutex
+	// generations of bases are incremented by writes, so responses
+	// of reads concurrent with a write are not cached.
+	generations map[string]uint64
+}
+
+func (c *clientCache) generation(db string) uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.generations[db]
+}
+
+func (c *clientCache) invalidate(db string, prefixes ...string) {
+	c.mu.Lock()
+	c.generations[db]++
+	c.mu.Unlock()
+	for _, prefix := range prefixes {
+		c.opts.Store.DeletePrefix(prefix)
+	}
+}
+
+// cacheKind kind of cached responses.
+type cacheKind int
+
+const (
+	cacheRecord cacheKind = iota
+	cacheRecords
+	cacheSchema
+)
+
+// cacheTTL returns TTL of the responses of the kind, zero if the cache is disabled.
+func (at *Client) cacheTTL(kind cacheKind) time.Duration {
+	if at.cache == nil {
+		return 0
+	}
+	switch kind {
+	case cacheRecord:
+		return at.cache.opts.RecordTTL
+	case cacheRecords:
+		return at.cache.opts.RecordsTTL
+	default:
+		return at.cache.opts.SchemaTTL
+	}
+}
+
+func recordCacheKey(db, table, recordID string, params url.Values) string {
+	return "record/" + db + "/" + recordID + "/" + table + "?" + params.Encode()
+}
+
+func recordsCacheKey(db, table string, params url.Values) string {
+	return "records/" + db + "/" + table + "?" + params.Encode()
+}
+
+func schemaCacheKey(db string) string {
+	return "schema/" + db
+}
+
+// noCacheKey context key of reads bypassing the cache.
+type noCacheKey struct{}
+
+// withoutCache returns ctx of reads that must see the current records,
+// such as reads before conditional writes and change scans.
+// Their responses are neither read from nor stored in the cache.
+func withoutCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noCacheKey{}, true)
+}
+
+// cached decodes the cached response to target or calls fetch
+// filling target and caches it for ttl.
+func (at *Client) cached(ctx context.Context, db, key string, ttl time.Duration, target any, fetch func() error) error {
+	c := at.cache
+	if c == nil || ttl <= 0 || ctx.Value(noCacheKey{}) != nil {
+		return fetch()
+	}
+	if b, ok := c.opts.Store.Get(key); ok && json.Unmarshal(b, target) == nil {
+		return nil
+	}
+
+	generation := c.generation(db)
+	if err := fetch(); err != nil {
+		return err
+	}
+	b, err := json.Marshal(target)
+	if err != nil {
+		return nil
+	}
+	if c.generation(db) == generation {
+		c.opts.Store.Set(key, b, ttl)
+	}
+	return nil
+}
+
+// invalidateRecords drops cached records and record lists of the base.
+// Lists of the whole base are dropped, since linked, lookup and rollup
+// fields of other tables may change too.
+func (at *Client) invalidateRecords(db string, recordIDs ...string) {
+	if at.cache == nil {
+		return
+	}
+	prefixes := []string{"records/" + db + "/"}
+	for _, id := range recordIDs {
+		prefixes = append(prefixes, "record/"+db+"/"+id+"/")
+	}
+	at.cache.invalidate(db, prefixes...)
+}
+
+// invalidateResponse drops cached records of the write response.
+func (at *Client) invalidateResponse(db, table string, response any) {
+	if at.cache == nil {
+		return
+	}
+	if db == "meta/bases" {
+		// schema write, table is "<base>/tables/..."
+		base, _, _ := strings.Cut(table, "/")
+		at.cache.invalidate(base, "schema/"+base, "records/"+base+"/", "record/"+base+"/")
+		return
+	}
+	var ids []string
+	if records, ok := response.(*Records); ok {
+		for _, record := range records.Records {
+			ids = append(ids, record.ID)
+		}
+	}
+	at.invalidateRecords(db, ids...)
+}
+
+// LRUCache in-memory CacheStore evicting least recently used values.
+type LRUCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	entries    map[string]*list.Element
+	order      *list.List
+}
+
+type lruEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+// NewLRUCache returns LRU cache of up to maxEntries values.
+func NewLRUCache(maxEntries int) *LRUCache {
+	return &LRUCache{
+		maxEntries: maxEntries,
+		entries:    map[string]*list.Element{},
+		order:      list.New(),
+	}
+}
+
+// Get returns the value if it is not expired.
+func (l *LRUCache) Get(key string) ([]byte, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	element, ok := l.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := element.Value.(*lruEntry)
+	if time.Now().After(entry.expires) {
+		l.remove(element)
+		return nil, false
+	}
+	l.order.MoveToFront(element)
+	return entry.value, true
+}
+
+// Set stores the value for ttl evicting the least recently used value if the cache is full.
+func (l *LRUCache) Set(key string, value []byte, ttl time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	entry := &lruEntry{key: key, value: value, expires: time.Now().Add(ttl)}
+	if element, ok := l.entries[key]; ok {
+		element.Value = entry
+		l.order.MoveToFront(element)
+		return
+	}
+	l.entries[key] = l.order.PushFront(entry)
+	for l.maxEntries > 0 && l.order.Len() > l.maxEntries {
+		l.remove(l.order.Back())
+	}
+}
+
+// DeletePrefix deletes values with keys starting with prefix.
+func (l *LRUCache) DeletePrefix(prefix string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for key, element := range l.entries {
+		if strings.HasPrefix(key, prefix) {
+			l.remove(element)
+		}
+	}
+}
+
+// Len returns number of stored values including expired ones.
+func (l *LRUCache) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.order.Len()
+}
+
+func (l *LRUCache) remove(element *list.Element) {
+	l.order.Remove(element)
+	delete(l.entries, element.Value.(*lruEntry).key)
+}
@@ -0,0 +1,140 @@
+// Copyright © 2020 Mike Berezin
+//
+// Use of this source code is governed by an MIT license.
+// Details in the LICENSE file.
+
+package airtable
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// mockCountingServer responds with records, record recA, schema or the request body
+// and counts requests by method and path.
+func mockCountingServer(t *testing.T, counts map[string]int) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		counts[r.Method+" "+r.URL.Path]++
+		switch {
+		case r.Method != http.MethodGet:
+			b, _ := io.ReadAll(r.Body)
+			_, _ = rw.Write(b)
+		case strings.HasPrefix(r.URL.Path, "/meta/"):
+			_, _ = rw.Write([]byte(`{"tables": [{"id": "tblA", "name": "Table"}]}`))
+		case strings.HasSuffix(r.URL.Path, "/recA"):
+			_, _ = rw.Write([]byte(`{"id": "recA", "fields": {"Name": "A"}}`))
+		default:
+			_, _ = rw.Write([]byte(`{"records": [{"id": "recA", "fields": {"Name": "A"}}]}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestClient_SetCache(t *testing.T) {
+	counts := map[string]int{}
+	client := testClient()
+	client.baseURL = mockCountingServer(t, counts).URL
+	client.SetCache(&CacheOptions{RecordTTL: time.Minute, RecordsTTL: time.Minute, SchemaTTL: time.Minute})
+	table := client.GetTable("dbName", "tableName")
+	params := url.Values{"view": {"Grid"}}
+
+	for i := 0; i < 2; i++ {
+		if _, err := table.GetRecord("recA"); err != nil {
+			t.Fatal(err)
+		}
+		records, err := table.GetRecordsWithParams(params)
+		if err != nil || len(records.Records) != 1 || records.Records[0].table != table {
+			t.Fatalf("unexpected records: %v, %v", records, err)
+		}
+		if _, err := client.GetBaseSchema("dbName").GetTables(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := table.GetRecordsWithParams(url.Values{"view": {"Other"}}); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]int{
+		"GET /dbName/tableName/recA":    1,
+		"GET /dbName/tableName":         2,
+		"GET /meta/bases/dbName/tables": 1,
+	}
+	for key, count := range expected {
+		if counts[key] != count {
+			t.Errorf("%s: expected %d requests, but was %d", key, count, counts[key])
+		}
+	}
+
+	record, err := table.GetRecord("recA")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := record.UpdateRecordPartial(map[string]any{"Name": "B"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := table.GetRecord("recA"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := table.GetRecordsWithParams(params); err != nil {
+		t.Fatal(err)
+	}
+	if counts["GET /dbName/tableName/recA"] != 2 || counts["GET /dbName/tableName"] != 3 {
+		t.Errorf("written record and lists should be fetched again, but was: %v", counts)
+	}
+
+	if _, err := client.GetBaseSchema("dbName").CreateField("tblA", &Field{Name: "Rooms", Type: "number"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.GetBaseSchema("dbName").GetTables(); err != nil {
+		t.Fatal(err)
+	}
+	if counts["GET /meta/bases/dbName/tables"] != 2 {
+		t.Errorf("schema should be fetched again after schema write, but was: %v", counts)
+	}
+
+	if _, err := table.GetRecordContext(withoutCache(context.Background()), "recA"); err != nil {
+		t.Fatal(err)
+	}
+	if counts["GET /dbName/tableName/recA"] != 3 {
+		t.Errorf("read without cache should be fetched, but was: %v", counts)
+	}
+
+	client.SetCache(nil)
+	if _, err := table.GetRecord("recA"); err != nil {
+		t.Fatal(err)
+	}
+	if counts["GET /dbName/tableName/recA"] != 4 {
+		t.Errorf("disabled cache should not be used, but was: %v", counts)
+	}
+}
+
+func TestLRUCache(t *testing.T) {
+	cache := NewLRUCache(2)
+	cache.Set("a", []byte("1"), time.Minute)
+	cache.Set("b", []byte("2"), time.Minute)
+	cache.Get("a")
+	cache.Set("c", []byte("3"), time.Minute)
+	if _, ok := cache.Get("b"); ok {
+		t.Error("least recently used value should be evicted")
+	}
+	if v, ok := cache.Get("a"); !ok || string(v) != "1" {
+		t.Errorf("unexpected value: %s, %v", v, ok)
+	}
+
+	cache.Set("d", []byte("4"), -time.Second)
+	if _, ok := cache.Get("d"); ok {
+		t.Error("expired value should not be returned")
+	}
+
+	cache.Set("record/a", []byte("5"), time.Minute)
+	cache.DeletePrefix("record/")
+	if _, ok := cache.Get("record/a"); ok || cache.Len() != 1 {
+		t.Errorf("values should be deleted by prefix, but was: %d", cache.Len())
+	}
+}
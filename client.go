@@ -31,6 +31,7 @@ type Client struct {
 	baseURL                 string
 	uploadAttachmentBaseURL string
 	apiKey                  string
+	cache                   *clientCache
 }
 
 // NewClient airtable client constructor
@@ -59,22 +60,44 @@ func (at *Client) SetRateLimit(customRateLimit int) {
 }
 
 func (at *Client) SetBaseURL(baseURL string) error {
+	url, err := parseBaseURL(baseURL)
+	if err != nil {
+		return err
+	}
+
+	at.baseURL = url
+
+	return nil
+}
+
+// SetUploadAttachmentBaseURL set base URL of the content API
+// used by UploadAttachment, https://content.airtable.com/v0 by default.
+func (at *Client) SetUploadAttachmentBaseURL(baseURL string) error {
+	url, err := parseBaseURL(baseURL)
+	if err != nil {
+		return err
+	}
+
+	at.uploadAttachmentBaseURL = url
+
+	return nil
+}
+
+func parseBaseURL(baseURL string) (string, error) {
 	url, err := url.Parse(baseURL)
 	if err != nil {
-		return fmt.Errorf("failed to parse baseURL: %s", err)
+		return "", fmt.Errorf("failed to parse baseURL: %s", err)
 	}
 
 	if url.Scheme == "" {
-		return fmt.Errorf("scheme of http or https must be specified")
+		return "", fmt.Errorf("scheme of http or https must be specified")
 	}
 
 	if url.Scheme != "https" && url.Scheme != "http" {
-		return fmt.Errorf("http or https baseURL must be used")
+		return "", fmt.Errorf("http or https baseURL must be used")
 	}
 
-	at.baseURL = url.String()
-
-	return nil
+	return url.String(), nil
 }
 
 func (at *Client) rateLimit(ctx context.Context) error {
@@ -111,27 +134,20 @@ func (at *Client) get(ctx context.Context, db, table, recordID string, params ur
 }
 
 func (at *Client) post(ctx context.Context, db, table string, data, response any) error {
-	err := at.rateLimit(ctx)
+	err := at.send(ctx, "POST", db, table, data, response)
 	if err != nil {
 		return err
 	}
 
-	url := fmt.Sprintf("%s/%s/%s", at.baseURL, db, table)
-
-	body, err := json.Marshal(data)
-	if err != nil {
-		return fmt.Errorf("cannot marshal body: %w", err)
-	}
+	at.invalidateResponse(db, table, response)
 
-	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
-	if err != nil {
-		return fmt.Errorf("cannot create request: %w", err)
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", at.apiKey))
+	return nil
+}
 
-	return at.do(req, response)
+// postRead sends POST request which does not change records,
+// such as listRecords, so the cache is not invalidated.
+func (at *Client) postRead(ctx context.Context, db, table string, data, response any) error {
+	return at.send(ctx, "POST", db, table, data, response)
 }
 
 func (at *Client) postAttachment(ctx context.Context, db, recordID string, attachmentFieldIdOrName string, data Attachment, response any) error {
@@ -155,7 +171,14 @@ func (at *Client) postAttachment(ctx context.Context, db, recordID string, attac
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", at.apiKey))
 
-	return at.do(req, response)
+	err = at.do(req, response)
+	if err != nil {
+		return err
+	}
+
+	at.invalidateRecords(db, recordID)
+
+	return nil
 }
 
 func (at *Client) delete(ctx context.Context, db, table string, recordIDs []string, target any) error {
@@ -186,34 +209,35 @@ func (at *Client) delete(ctx context.Context, db, table string, recordIDs []stri
 		return err
 	}
 
+	at.invalidateRecords(db, recordIDs...)
+
 	return nil
 }
 
-func (at *Client) patch(ctx context.Context, db, table, data, response any) error {
-	err := at.rateLimit(ctx)
+func (at *Client) patch(ctx context.Context, db, table string, data, response any) error {
+	err := at.send(ctx, "PATCH", db, table, data, response)
 	if err != nil {
 		return err
 	}
 
-	url := fmt.Sprintf("%s/%s/%s", at.baseURL, db, table)
+	at.invalidateResponse(db, table, response)
 
-	body, err := json.Marshal(data)
-	if err != nil {
-		return fmt.Errorf("cannot marshal body: %w", err)
-	}
+	return nil
+}
 
-	req, err := http.NewRequestWithContext(ctx, "PATCH", url, bytes.NewReader(body))
+func (at *Client) put(ctx context.Context, db, table string, data, response any) error {
+	err := at.send(ctx, "PUT", db, table, data, response)
 	if err != nil {
-		return fmt.Errorf("cannot create request: %w", err)
+		return err
 	}
 
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", at.apiKey))
+	at.invalidateResponse(db, table, response)
 
-	return at.do(req, response)
+	return nil
 }
 
-func (at *Client) put(ctx context.Context, db, table, data, response any) error {
+// send sends JSON body with the method to the table and decodes the response.
+func (at *Client) send(ctx context.Context, method, db, table string, data, response any) error {
 	err := at.rateLimit(ctx)
 	if err != nil {
 		return err
@@ -226,7 +250,7 @@ func (at *Client) put(ctx context.Context, db, table, data, response any) error
 		return fmt.Errorf("cannot marshal body: %w", err)
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "PUT", url, bytes.NewReader(body))
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
 	if err != nil {
 		return fmt.Errorf("cannot create request: %w", err)
 	}
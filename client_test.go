@@ -80,3 +80,17 @@ func TestClient_SetBaseURL(t *testing.T) {
 		})
 	}
 }
+
+func TestClient_SetUploadAttachmentBaseURL(t *testing.T) {
+	c := testClient()
+
+	if err := c.SetUploadAttachmentBaseURL("http://localhost:3000"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if c.uploadAttachmentBaseURL != "http://localhost:3000" {
+		t.Errorf("unexpected upload attachment base URL: %s", c.uploadAttachmentBaseURL)
+	}
+	if err := c.SetUploadAttachmentBaseURL("ftp://example.com"); err == nil {
+		t.Errorf("there should be an err, but was nil")
+	}
+}
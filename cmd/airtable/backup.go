@@ -0,0 +1,75 @@
+// Copyright © 2020 Mike Berezin
+//
+// Use of this source code is governed by an MIT license.
+// Details in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/mehanizm/airtable/backup"
+)
+
+func runBackup(args []string) error {
+	var bf baseFlags
+	fs := flag.NewFlagSet("backup", flag.ExitOnError)
+	bf.register(fs)
+	attachments := fs.Bool("attachments", false, "download attachment files")
+	tables := fs.String("tables", "", "comma separated tables to back up, all tables by default")
+	_ = fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		return fmt.Errorf("backup directory is required")
+	}
+
+	client, err := bf.client()
+	if err != nil {
+		return err
+	}
+
+	opts := backup.Options{Attachments: *attachments}
+	if *tables != "" {
+		opts.Tables = strings.Split(*tables, ",")
+	}
+
+	manifest, err := backup.Backup(ctx(), client, bf.base, fs.Arg(0), opts)
+	if err != nil {
+		return err
+	}
+	for _, table := range manifest.Tables {
+		fmt.Printf("%s: %d records", table.Name, table.Records)
+		if len(table.MissingAttachments) > 0 {
+			fmt.Printf(", %d attachments failed to download", len(table.MissingAttachments))
+		}
+		fmt.Println()
+	}
+	return nil
+}
+
+func runRestore(args []string) error {
+	var bf baseFlags
+	fs := flag.NewFlagSet("restore", flag.ExitOnError)
+	bf.register(fs)
+	_ = fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		return fmt.Errorf("backup directory is required")
+	}
+
+	client, err := bf.client()
+	if err != nil {
+		return err
+	}
+
+	result, err := backup.Restore(ctx(), client, fs.Arg(0), bf.base)
+	if result != nil {
+		for _, skipped := range result.Skipped {
+			fmt.Println("skipped", skipped)
+		}
+		fmt.Printf("tables: %d, records: %d\n", len(result.Tables), len(result.Records))
+	}
+	return err
+}
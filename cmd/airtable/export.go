@@ -0,0 +1,90 @@
+// Copyright © 2020 Mike Berezin
+//
+// Use of this source code is governed by an MIT license.
+// Details in the LICENSE file.
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/mehanizm/airtable/exporter"
+)
+
+func runExport(args []string) error {
+	var tf tableFlags
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	tf.register(fs)
+	format := fs.String("format", "csv", "output format: csv, jsonl, ndjson or xlsx")
+	view := fs.String("view", "", "view name or ID")
+	formula := fs.String("formula", "", "filter by formula")
+	fields := fs.String("fields", "", "comma separated fields to export")
+	stringFormat := fs.Bool("string-format", false, "export cell values formatted as strings")
+	timeZone := fs.String("time-zone", "UTC", "time zone of string format")
+	userLocale := fs.String("user-locale", "en", "user locale of string format")
+	includeID := fs.Bool("id", false, "add record ID column to CSV and XLSX")
+	output := fs.String("o", "", "file to write to, stdout by default")
+	checkpoint := fs.String("checkpoint", "", "file to save progress to, resumes the export appending to the output")
+	_ = fs.Parse(args)
+
+	opts := exporter.Options{
+		View:         *view,
+		Formula:      *formula,
+		StringFormat: *stringFormat,
+		TimeZone:     *timeZone,
+		UserLocale:   *userLocale,
+		IncludeID:    *includeID,
+	}
+	switch *format {
+	case "csv":
+		opts.Format = exporter.CSV
+	case "jsonl", "ndjson":
+		opts.Format = exporter.JSONLines
+	case "xlsx":
+		opts.Format = exporter.XLSX
+	default:
+		return fmt.Errorf("unknown format %q", *format)
+	}
+	if *fields != "" {
+		opts.Fields = strings.Split(*fields, ",")
+	}
+	flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if *checkpoint != "" {
+		if *output == "" || opts.Format == exporter.XLSX {
+			return fmt.Errorf("checkpoint requires -o and csv or jsonl format")
+		}
+		store := exporter.FileCheckpoint(*checkpoint)
+		opts.Checkpoint = store
+		saved, err := store.Load()
+		if err != nil {
+			return err
+		}
+		if saved != nil {
+			flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+		}
+	}
+
+	client, err := tf.client()
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		f, err := os.OpenFile(*output, flags, 0o644)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	table := client.GetTable(tf.base, tf.table)
+	count, err := exporter.New(table, opts).Export(ctx(), w)
+	fmt.Fprintf(os.Stderr, "exported: %d\n", count)
+	return err
+}
@@ -0,0 +1,113 @@
+// Copyright © 2020 Mike Berezin
+//
+// Use of this source code is governed by an MIT license.
+// Details in the LICENSE file.
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mehanizm/airtable/importer"
+)
+
+func runImport(args []string) error {
+	var tf tableFlags
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	tf.register(fs)
+	mappingFile := fs.String("mapping", "", "JSON file mapping column names to field names")
+	typecast := fs.Bool("typecast", false, "send text values and let Airtable convert them")
+	mergeOn := fs.String("merge-on", "", "comma separated fields to upsert records on")
+	rejectsFile := fs.String("rejects", "rejects.csv", "file to write rejected rows to, rejects.xlsx by default for XLSX input")
+	timeZone := fs.String("time-zone", "", "time zone of values of date fields with client time zone")
+	sheet := fs.String("sheet", "", "XLSX sheet to import, the sheet named after the table or the first one by default")
+	_ = fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		return fmt.Errorf("CSV or XLSX file is required")
+	}
+	isXLSX := strings.EqualFold(filepath.Ext(fs.Arg(0)), ".xlsx")
+	if isXLSX && !isFlagSet(fs, "rejects") {
+		*rejectsFile = "rejects.xlsx"
+	}
+
+	client, err := tf.client()
+	if err != nil {
+		return err
+	}
+
+	ctx := ctx()
+
+	schema, err := client.GetBaseSchema(tf.base).GetTablesContext(ctx)
+	if err != nil {
+		return fmt.Errorf("cannot get base schema: %w", err)
+	}
+	tableSchema := schema.Table(tf.table)
+	if tableSchema == nil {
+		return fmt.Errorf("table %q not found in base", tf.table)
+	}
+
+	opts := importer.Options{Typecast: *typecast, TimeZone: *timeZone}
+	if *mergeOn != "" {
+		opts.MergeOn = strings.Split(*mergeOn, ",")
+	}
+	if *mappingFile != "" {
+		b, err := os.ReadFile(*mappingFile)
+		if err != nil {
+			return fmt.Errorf("cannot read mapping: %w", err)
+		}
+		if err := json.Unmarshal(b, &opts.Mapping); err != nil {
+			return fmt.Errorf("cannot decode mapping: %w", err)
+		}
+	}
+
+	input, err := os.Open(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer input.Close()
+
+	var rejects io.Writer = io.Discard
+	if *rejectsFile != "" {
+		f, err := os.Create(*rejectsFile)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		rejects = f
+	}
+
+	table := client.GetTable(tf.base, tf.table)
+	im := importer.New(table, tableSchema, opts)
+	var result *importer.Result
+	if isXLSX {
+		var info os.FileInfo
+		if info, err = input.Stat(); err != nil {
+			return err
+		}
+		result, err = im.ImportXLSX(ctx, input, info.Size(), *sheet, rejects)
+	} else {
+		result, err = im.ImportCSV(ctx, input, rejects)
+	}
+	if result != nil {
+		fmt.Printf("created: %d, updated: %d, rejected: %d\n", result.Created, result.Updated, result.Rejected)
+	}
+	return err
+}
+
+// isFlagSet reports whether the flag was passed on the command line.
+func isFlagSet(fs *flag.FlagSet, name string) bool {
+	set := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
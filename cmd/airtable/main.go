@@ -0,0 +1,105 @@
+// Copyright © 2020 Mike Berezin
+//
+// Use of this source code is governed by an MIT license.
+// Details in the LICENSE file.
+
+// Command airtable works with Airtable tables from the command line.
+//
+//	airtable import -base appXXX -table Table [-mapping mapping.json] [-typecast] [-merge-on Field] [-rejects rejects.csv] [-sheet Sheet] file.csv|file.xlsx
+//	airtable export -base appXXX -table Table [-format csv|jsonl|xlsx] [-view View] [-formula Formula] [-fields Field1,Field2] [-o file]
+//	airtable backup -base appXXX [-attachments] [-tables Table1,Table2] dir
+//	airtable restore -base appYYY dir
+//
+// API key is read from -key flag or AIRTABLE_API_KEY environment variable.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/mehanizm/airtable"
+)
+
+const usage = `usage: airtable <command> [flags]
+
+commands:
+  import  import CSV or XLSX file into a table
+  export  export records of a table to CSV, JSON Lines or XLSX
+  backup  back up schema and records of a base to a directory
+  restore restore a backup into an empty base
+
+run "airtable <command> -h" for command flags`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	var err error
+	switch os.Args[1] {
+	case "import":
+		err = runImport(os.Args[2:])
+	case "export":
+		err = runExport(os.Args[2:])
+	case "backup":
+		err = runBackup(os.Args[2:])
+	case "restore":
+		err = runRestore(os.Args[2:])
+	default:
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// ctx returns context canceled on interrupt.
+func ctx() context.Context {
+	ctx, _ := signal.NotifyContext(context.Background(), os.Interrupt)
+	return ctx
+}
+
+// baseFlags flags to access a base.
+type baseFlags struct {
+	apiKey string
+	base   string
+}
+
+func (f *baseFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.apiKey, "key", os.Getenv("AIRTABLE_API_KEY"), "Airtable API key, AIRTABLE_API_KEY by default")
+	fs.StringVar(&f.base, "base", "", "base ID")
+}
+
+func (f *baseFlags) client() (*airtable.Client, error) {
+	if f.apiKey == "" {
+		return nil, fmt.Errorf("API key is required")
+	}
+	if f.base == "" {
+		return nil, fmt.Errorf("base is required")
+	}
+	return airtable.NewClient(f.apiKey), nil
+}
+
+// tableFlags flags to access a table.
+type tableFlags struct {
+	baseFlags
+	table string
+}
+
+func (f *tableFlags) register(fs *flag.FlagSet) {
+	f.baseFlags.register(fs)
+	fs.StringVar(&f.table, "table", "", "table name or ID")
+}
+
+func (f *tableFlags) client() (*airtable.Client, error) {
+	if f.table == "" {
+		return nil, fmt.Errorf("table is required")
+	}
+	return f.baseFlags.client()
+}
@@ -6,21 +6,60 @@
 package airtable
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 )
 
+// ErrIteratorNotAvailable wrapped by HTTPClientError when the offset
+// of records list is expired, listing should be restarted from the first page.
+var ErrIteratorNotAvailable = errors.New("records iterator is not available")
+
+// typeErrors sentinel errors wrapped by HTTPClientError of the error types.
+var typeErrors = map[string]error{
+	"LIST_RECORDS_ITERATOR_NOT_AVAILABLE": ErrIteratorNotAvailable,
+}
+
 // HTTPClientError custom error to handle with response status.
 type HTTPClientError struct {
 	StatusCode int
-	Err        error
+	// Type of the error from the Airtable response body, like INVALID_REQUEST_UNKNOWN.
+	Type string
+	Err  error
 }
 
 func (e *HTTPClientError) Error() string {
 	return fmt.Sprintf("status %d, err: %v", e.StatusCode, e.Err)
 }
 
+func (e *HTTPClientError) Unwrap() error {
+	return e.Err
+}
+
+// errorType extracts error type from Airtable response body
+// {"error": {"type": "..."}} or {"error": "..."}.
+func errorType(body []byte) string {
+	var response struct {
+		Error json.RawMessage `json:"error"`
+	}
+	if json.Unmarshal(body, &response) != nil || len(response.Error) == 0 {
+		return ""
+	}
+	var errorObject struct {
+		Type string `json:"type"`
+	}
+	if json.Unmarshal(response.Error, &errorObject) == nil {
+		return errorObject.Type
+	}
+	var errorString string
+	if json.Unmarshal(response.Error, &errorString) == nil {
+		return errorString
+	}
+	return ""
+}
+
 func makeHTTPClientError(url string, resp *http.Response) error {
 	var resError error
 
@@ -48,6 +87,7 @@ func makeHTTPClientError(url string, resp *http.Response) error {
 		respStatusText = "The server could not process your request in time. The server could be temporarily unavailable, or it could have timed out processing your request. You should retry the request with backoffs."
 	}
 
+	errType := ""
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		resError = fmt.Errorf("HTTP request failure on %s:\n%d %s\n%s\n\nCannot parse body with err: %w",
@@ -55,10 +95,15 @@ func makeHTTPClientError(url string, resp *http.Response) error {
 	} else {
 		resError = fmt.Errorf("HTTP request failure on %s:\n%d %s\n%s\n\nBody: %v",
 			url, resp.StatusCode, resp.Status, respStatusText, string(body))
+		errType = errorType(body)
+		if typeErr, ok := typeErrors[errType]; ok {
+			resError = fmt.Errorf("%w: %w", typeErr, resError)
+		}
 	}
 
 	return &HTTPClientError{
 		StatusCode: resp.StatusCode,
+		Type:       errType,
 		Err:        resError,
 	}
 }
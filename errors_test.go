@@ -54,3 +54,27 @@ Body: body`,
 		})
 	}
 }
+
+func Test_makeHTTPClientError_Type(t *testing.T) {
+	tests := []struct {
+		body     string
+		expected string
+	}{
+		{`{"error": {"type": "LIST_RECORDS_ITERATOR_NOT_AVAILABLE", "message": "expired"}}`, "LIST_RECORDS_ITERATOR_NOT_AVAILABLE"},
+		{`{"error": "NOT_FOUND"}`, "NOT_FOUND"},
+		{`not json`, ""},
+	}
+	for _, tt := range tests {
+		err := makeHTTPClientError("url", &http.Response{
+			StatusCode: 422,
+			Body:       io.NopCloser(bytes.NewReader([]byte(tt.body))),
+		})
+		var httpErr *HTTPClientError
+		if !errors.As(err, &httpErr) || httpErr.Type != tt.expected {
+			t.Errorf("expected type %q, but was: %#v", tt.expected, err)
+		}
+		if is := errors.Is(err, ErrIteratorNotAvailable); is != (tt.expected == "LIST_RECORDS_ITERATOR_NOT_AVAILABLE") {
+			t.Errorf("unexpected errors.Is(%q, ErrIteratorNotAvailable) = %v", tt.body, is)
+		}
+	}
+}
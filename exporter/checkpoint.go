@@ -0,0 +1,136 @@
+// Copyright © 2020 Mike Berezin
+//
+// Use of this source code is governed by an MIT license.
+// Details in the LICENSE file.
+
+package exporter
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// ErrCheckpointMismatch returned when the saved checkpoint
+// belongs to an export of another table or with other options.
+var ErrCheckpointMismatch = errors.New("checkpoint was saved for other export parameters")
+
+// Checkpoint progress of the export.
+type Checkpoint struct {
+	// Offset of the next page.
+	Offset string `json:"offset"`
+	// Count of exported records.
+	Count int `json:"count"`
+	// ParamsHash of the table and options of the export.
+	ParamsHash string `json:"paramsHash"`
+	// IDs of exported records, to skip them if the scan is restarted.
+	IDs []string `json:"-"`
+}
+
+// CheckpointStore persists progress of the export to resume it.
+type CheckpointStore interface {
+	// Load returns the saved checkpoint or nil if there is none.
+	Load() (*Checkpoint, error)
+	// Save saves checkpoint after a page is written and flushed,
+	// ids are the records exported in the page.
+	Save(checkpoint *Checkpoint, ids []string) error
+	// Clear removes the checkpoint when the export is finished.
+	Clear() error
+}
+
+// FileCheckpoint stores checkpoint as JSON in the file
+// and IDs of exported records in the file with ".ids" suffix.
+type FileCheckpoint string
+
+func (f FileCheckpoint) idsPath() string {
+	return string(f) + ".ids"
+}
+
+// Load reads checkpoint from the files.
+func (f FileCheckpoint) Load() (*Checkpoint, error) {
+	b, err := os.ReadFile(string(f))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	checkpoint := new(Checkpoint)
+	if err := json.Unmarshal(b, checkpoint); err != nil {
+		return nil, fmt.Errorf("cannot decode checkpoint: %w", err)
+	}
+
+	ids, err := os.Open(f.idsPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return checkpoint, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer ids.Close()
+	scanner := bufio.NewScanner(ids)
+	for scanner.Scan() {
+		if id := strings.TrimSpace(scanner.Text()); id != "" {
+			checkpoint.IDs = append(checkpoint.IDs, id)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	// IDs are appended before the checkpoint is written,
+	// so they are ahead of the count after an interrupted save.
+	checkpoint.Count = max(checkpoint.Count, len(checkpoint.IDs))
+	return checkpoint, nil
+}
+
+// Save appends IDs and atomically replaces the checkpoint file.
+func (f FileCheckpoint) Save(checkpoint *Checkpoint, ids []string) error {
+	if len(ids) > 0 {
+		file, err := os.OpenFile(f.idsPath(), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return err
+		}
+		_, err = file.WriteString(strings.Join(ids, "\n") + "\n")
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	b, err := json.Marshal(checkpoint)
+	if err != nil {
+		return err
+	}
+	tmp := string(f) + ".tmp"
+	if err := os.WriteFile(tmp, b, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, string(f))
+}
+
+// Clear removes the checkpoint files.
+func (f FileCheckpoint) Clear() error {
+	for _, path := range []string{string(f), f.idsPath()} {
+		if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+	}
+	return nil
+}
+
+// paramsHash identifies the table and the options changing exported records.
+func (e *Exporter) paramsHash() string {
+	b, _ := json.Marshal([]any{
+		e.table.DBName(), e.table.TableName(), e.opts.Format, e.opts.View, e.opts.Fields, e.opts.Formula,
+		e.opts.StringFormat, e.opts.TimeZone, e.opts.UserLocale, e.opts.IncludeID,
+	})
+	hash := sha256.Sum256(b)
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,102 @@
+// Copyright © 2020 Mike Berezin
+//
+// Use of this source code is governed by an MIT license.
+// Details in the LICENSE file.
+
+package exporter
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mehanizm/airtable"
+)
+
+func TestExporter_ExportResume(t *testing.T) {
+	var queries []url.Values
+	failures := 1
+	table := failingTestServer(t, &queries, func(r *http.Request) (int, string) {
+		if r.URL.Query().Get("offset") != "" && failures > 0 {
+			failures--
+			return http.StatusServiceUnavailable, "unavailable"
+		}
+		return 0, ""
+	})
+
+	store := FileCheckpoint(filepath.Join(t.TempDir(), "export.checkpoint"))
+	opts := Options{Format: CSV, Fields: []string{"Name"}, Checkpoint: store}
+
+	var buf bytes.Buffer
+	count, err := New(table, opts).Export(context.Background(), &buf)
+	var httpErr *airtable.HTTPClientError
+	if !errors.As(err, &httpErr) || count != 1 {
+		t.Fatalf("export should fail on the second page after 1 record, but was: %d, %v", count, err)
+	}
+	checkpoint, err := store.Load()
+	if err != nil || checkpoint == nil || checkpoint.Offset != "itr1/rec1" || checkpoint.Count != 1 || len(checkpoint.IDs) != 1 {
+		t.Fatalf("checkpoint should be saved after the first page, but was: %+v, %v", checkpoint, err)
+	}
+
+	count, err = New(table, opts).Export(context.Background(), &buf)
+	if err != nil || count != 2 {
+		t.Fatalf("export should be resumed, but was: %d, %v", count, err)
+	}
+	expected := "Name\n\"Loft, \"\"Downtown\"\"\"\nStudio\n"
+	if buf.String() != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, buf.String())
+	}
+	if last := queries[len(queries)-1]; last.Get("offset") != "itr1/rec1" {
+		t.Errorf("resumed export should start from the saved offset, but was: %v", last)
+	}
+	if _, err := os.Stat(string(store)); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("checkpoint should be cleared, but was: %v", err)
+	}
+}
+
+func TestExporter_ExportExpiredOffset(t *testing.T) {
+	var queries []url.Values
+	expired := 1
+	table := failingTestServer(t, &queries, func(r *http.Request) (int, string) {
+		if r.URL.Query().Get("offset") != "" && expired > 0 {
+			expired--
+			return http.StatusUnprocessableEntity, `{"error": {"type": "LIST_RECORDS_ITERATOR_NOT_AVAILABLE"}}`
+		}
+		return 0, ""
+	})
+
+	var buf bytes.Buffer
+	count, err := New(table, Options{Format: JSONLines}).Export(context.Background(), &buf)
+	if err != nil || count != 2 {
+		t.Fatalf("export should be restarted, but was: %d, %v", count, err)
+	}
+	if lines := strings.Count(buf.String(), "\n"); lines != 2 {
+		t.Errorf("records should not be duplicated, but was:\n%s", buf.String())
+	}
+	if len(queries) != 4 || queries[2].Get("offset") != "" {
+		t.Errorf("scan should be restarted from the first page, but queries were: %v", queries)
+	}
+}
+
+func TestExporter_ExportCheckpointMismatch(t *testing.T) {
+	var queries []url.Values
+	table := testServer(t, &queries)
+	store := FileCheckpoint(filepath.Join(t.TempDir(), "export.checkpoint"))
+	if err := store.Save(&Checkpoint{Offset: "itr1/rec1", ParamsHash: "other"}, []string{"rec1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := New(table, Options{Format: CSV, Checkpoint: store}).Export(context.Background(), &bytes.Buffer{})
+	if !errors.Is(err, ErrCheckpointMismatch) {
+		t.Errorf("should be ErrCheckpointMismatch, but was: %v", err)
+	}
+	if len(queries) != 0 {
+		t.Errorf("records should not be requested, but were: %v", queries)
+	}
+}
@@ -0,0 +1,352 @@
+// Copyright © 2020 Mike Berezin
+//
+// Use of this source code is governed by an MIT license.
+// Details in the LICENSE file.
+
+// Package exporter streams all records of an Airtable table or view
+// to CSV, JSON Lines or XLSX.
+package exporter
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/mehanizm/airtable"
+)
+
+// Format of the export.
+type Format int
+
+const (
+	// CSV with header of field names, multi-value cells are joined with ", "
+	// and attachments are written as URLs.
+	CSV Format = iota
+	// JSONLines one JSON record with id, createdTime and fields per line.
+	JSONLines
+	// XLSX workbook with one sheet named after the table, cells are typed
+	// by the schema: numbers, booleans, dates and hyperlinks for attachments and URLs.
+	XLSX
+	// NDJSON is the same as JSONLines.
+	NDJSON = JSONLines
+)
+
+const (
+	// valuesSeparator joins multi-value cells in CSV.
+	valuesSeparator = ", "
+	// maxRestarts of the scan after expired offset.
+	maxRestarts = 3
+	// defaultUserLocale of string values if UserLocale is empty.
+	defaultUserLocale = "en-us"
+)
+
+// Options of the export.
+type Options struct {
+	Format Format
+	// View name or ID to export records of.
+	View string
+	// Fields to export in this order, all fields of the schema if empty.
+	Fields []string
+	// Formula to filter records by.
+	Formula string
+	// StringFormat requests cell values formatted as strings by Airtable
+	// in TimeZone and UserLocale.
+	StringFormat bool
+	// TimeZone of string values and of XLSX cells of fields
+	// with "client" time zone, UTC if empty.
+	TimeZone string
+	// UserLocale of string values, "en-us" if empty.
+	UserLocale string
+	// IncludeID adds "id" column with record ID as the first CSV or XLSX column.
+	IncludeID bool
+	// Schema of the table to order CSV columns by and to type XLSX cells,
+	// requested with Table.GetSchema if not set and needed.
+	Schema *airtable.TableSchema
+	// Checkpoint to save progress to and resume from, not used for XLSX.
+	Checkpoint CheckpointStore
+}
+
+// Exporter writes records of the table.
+type Exporter struct {
+	table *airtable.Table
+	opts  Options
+}
+
+// New creates exporter of the table.
+func New(table *airtable.Table, opts Options) *Exporter {
+	return &Exporter{
+		table: table,
+		opts:  opts,
+	}
+}
+
+// recordWriter writes records in one format.
+type recordWriter interface {
+	writeHeader() error
+	write(record *airtable.Record) error
+	flush() error
+}
+
+// Export walks all pages and streams records to w.
+// Returns number of exported records.
+//
+// With Checkpoint option the progress is saved after each page
+// and a saved export is resumed: the header is not written again,
+// so w must append to the output of the interrupted export.
+// Exported records are remembered, so if the offset is expired
+// the scan is restarted from the first page without duplicates.
+// The output is at least once: the checkpoint is saved after the page is written,
+// so records of the page written before a crash are written again on resume.
+func (e *Exporter) Export(ctx context.Context, w io.Writer) (int, error) {
+	if e.opts.Format == XLSX {
+		return ExportXLSX(ctx, w, e)
+	}
+	writer, err := e.writer(ctx, w)
+	if err != nil {
+		return 0, err
+	}
+	return e.export(ctx, writer, e.opts.Checkpoint)
+}
+
+// export writes header and all pages of records with the writer.
+// store may be nil.
+func (e *Exporter) export(ctx context.Context, writer recordWriter, store CheckpointStore) (int, error) {
+	checkpoint := &Checkpoint{ParamsHash: e.paramsHash()}
+	resumed := false
+	if store != nil {
+		saved, err := store.Load()
+		if err != nil {
+			return 0, fmt.Errorf("cannot load checkpoint: %w", err)
+		}
+		if saved != nil {
+			if saved.ParamsHash != checkpoint.ParamsHash {
+				return 0, ErrCheckpointMismatch
+			}
+			checkpoint, resumed = saved, true
+		}
+	}
+
+	exported := make(map[string]struct{}, len(checkpoint.IDs))
+	for _, id := range checkpoint.IDs {
+		exported[id] = struct{}{}
+	}
+
+	if !resumed {
+		if err := writer.writeHeader(); err != nil {
+			return 0, err
+		}
+	}
+
+	grc := e.records()
+	if checkpoint.Offset != "" {
+		grc.WithOffset(checkpoint.Offset)
+	}
+	restarts := 0
+	for {
+		records, err := grc.DoContext(ctx)
+		if errors.Is(err, airtable.ErrIteratorNotAvailable) && restarts < maxRestarts {
+			restarts++
+			grc = e.records()
+			continue
+		}
+		if err != nil {
+			return checkpoint.Count, err
+		}
+
+		var ids []string
+		for _, record := range records.Records {
+			if _, ok := exported[record.ID]; ok {
+				continue
+			}
+			if err := writer.write(record); err != nil {
+				return checkpoint.Count, err
+			}
+			exported[record.ID] = struct{}{}
+			ids = append(ids, record.ID)
+			checkpoint.Count++
+		}
+		if err := writer.flush(); err != nil {
+			return checkpoint.Count, err
+		}
+
+		if records.Offset == "" {
+			if store != nil {
+				if err := store.Clear(); err != nil {
+					return checkpoint.Count, fmt.Errorf("cannot clear checkpoint: %w", err)
+				}
+			}
+			return checkpoint.Count, nil
+		}
+		grc.WithOffset(records.Offset)
+		if store != nil {
+			checkpoint.Offset = records.Offset
+			if err := store.Save(checkpoint, ids); err != nil {
+				return checkpoint.Count, fmt.Errorf("cannot save checkpoint: %w", err)
+			}
+		}
+	}
+}
+
+// timeZone returns time zone of the values of fields with "client" time zone.
+func (e *Exporter) timeZone() string {
+	if e.opts.TimeZone == "" {
+		return "UTC"
+	}
+	return e.opts.TimeZone
+}
+
+// userLocale returns locale of string values.
+func (e *Exporter) userLocale() string {
+	if e.opts.UserLocale == "" {
+		return defaultUserLocale
+	}
+	return e.opts.UserLocale
+}
+
+// records prepares get records request with the options.
+func (e *Exporter) records() *airtable.GetRecordsConfig {
+	grc := e.table.GetRecords()
+	if e.opts.View != "" {
+		grc.FromView(e.opts.View)
+	}
+	if e.opts.Formula != "" {
+		grc.WithFilterFormula(e.opts.Formula)
+	}
+	if len(e.opts.Fields) > 0 {
+		grc.ReturnFields(e.opts.Fields...)
+	}
+	if e.opts.StringFormat {
+		grc.InStringFormat(e.timeZone(), e.userLocale())
+	}
+	return grc
+}
+
+func (e *Exporter) writer(ctx context.Context, w io.Writer) (recordWriter, error) {
+	switch e.opts.Format {
+	case CSV:
+		columns, err := e.columns(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return &csvWriter{w: csv.NewWriter(w), columns: columns, includeID: e.opts.IncludeID}, nil
+	case JSONLines:
+		return &jsonLinesWriter{enc: json.NewEncoder(w)}, nil
+	}
+	return nil, fmt.Errorf("unknown export format %d", e.opts.Format)
+}
+
+// columns returns CSV columns: selected fields or all fields of the schema.
+func (e *Exporter) columns(ctx context.Context) ([]string, error) {
+	if len(e.opts.Fields) > 0 {
+		return e.opts.Fields, nil
+	}
+	schema, err := e.schema(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return schemaColumns(schema), nil
+}
+
+// schema returns schema from the options or requests it once.
+func (e *Exporter) schema(ctx context.Context) (*airtable.TableSchema, error) {
+	if e.opts.Schema == nil {
+		schema, err := e.table.GetSchemaContext(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("cannot get table schema: %w", err)
+		}
+		e.opts.Schema = schema
+	}
+	return e.opts.Schema, nil
+}
+
+func schemaColumns(schema *airtable.TableSchema) []string {
+	columns := make([]string, 0, len(schema.Fields))
+	for _, field := range schema.Fields {
+		columns = append(columns, field.Name)
+	}
+	return columns
+}
+
+type csvWriter struct {
+	w         *csv.Writer
+	columns   []string
+	includeID bool
+}
+
+func (c *csvWriter) writeHeader() error {
+	header := c.columns
+	if c.includeID {
+		header = append([]string{"id"}, header...)
+	}
+	return c.w.Write(header)
+}
+
+func (c *csvWriter) write(record *airtable.Record) error {
+	row := make([]string, 0, len(c.columns)+1)
+	if c.includeID {
+		row = append(row, record.ID)
+	}
+	for _, column := range c.columns {
+		row = append(row, FormatValue(record.Fields[column]))
+	}
+	return c.w.Write(row)
+}
+
+func (c *csvWriter) flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+type jsonLinesWriter struct {
+	enc *json.Encoder
+}
+
+func (j *jsonLinesWriter) writeHeader() error {
+	return nil
+}
+
+func (j *jsonLinesWriter) write(record *airtable.Record) error {
+	return j.enc.Encode(record)
+}
+
+func (j *jsonLinesWriter) flush() error {
+	return nil
+}
+
+// FormatValue formats cell value as text: numbers without exponent,
+// list items joined with ", ", attachments as URLs, collaborators as emails
+// and objects (barcode, button, AI text) by their text value.
+func FormatValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	case []any:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			items = append(items, FormatValue(item))
+		}
+		return strings.Join(items, valuesSeparator)
+	case map[string]any:
+		for _, key := range []string{"url", "email", "text", "value", "name", "label", "id"} {
+			if s, ok := v[key].(string); ok && s != "" {
+				return s
+			}
+		}
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(b)
+}
@@ -0,0 +1,260 @@
+// Copyright © 2020 Mike Berezin
+//
+// Use of this source code is governed by an MIT license.
+// Details in the LICENSE file.
+
+package exporter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mehanizm/airtable"
+	"github.com/mehanizm/airtable/xlsx"
+)
+
+// testServer serves base schema and two pages of Apartments records
+// and records query params of the records requests.
+func testServer(t *testing.T, queries *[]url.Values) *airtable.Table {
+	return failingTestServer(t, queries, nil)
+}
+
+// failingTestServer is testServer which responds to records requests
+// with the error from fail if it is not nil.
+func failingTestServer(t *testing.T, queries *[]url.Values, fail func(r *http.Request) (int, string)) *airtable.Table {
+	t.Helper()
+	serve := func(w http.ResponseWriter, file string) {
+		b, err := os.ReadFile("testdata/" + file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, _ = w.Write(b)
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, "/meta/bases/") && fail != nil {
+			if code, body := fail(r); code != 0 {
+				*queries = append(*queries, r.URL.Query())
+				http.Error(w, body, code)
+				return
+			}
+		}
+		switch {
+		case strings.HasPrefix(r.URL.Path, "/meta/bases/"):
+			serve(w, "base_schema.json")
+		case r.URL.Query().Get("offset") == "":
+			*queries = append(*queries, r.URL.Query())
+			serve(w, "apartments_page1.json")
+		default:
+			*queries = append(*queries, r.URL.Query())
+			serve(w, "apartments_page2.json")
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	client := airtable.NewClient("apiKey")
+	client.SetRateLimit(1000)
+	if err := client.SetBaseURL(server.URL); err != nil {
+		t.Fatal(err)
+	}
+	return client.GetTable("appBase", "Apartments")
+}
+
+func TestExporter_ExportCSV(t *testing.T) {
+	var queries []url.Values
+	table := testServer(t, &queries)
+
+	var buf bytes.Buffer
+	count, err := New(table, Options{Format: CSV, IncludeID: true}).Export(context.Background(), &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Errorf("expected 2 records, got %d", count)
+	}
+	expected := "id,Name,Pictures,District\n" +
+		"rec1,\"Loft, \"\"Downtown\"\"\",\"https://example.com/1.jpg, https://example.com/2.jpg\",recD1\n" +
+		"rec2,Studio,,\n"
+	if buf.String() != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, buf.String())
+	}
+	if len(queries) != 2 || queries[1].Get("offset") != "itr1/rec1" {
+		t.Errorf("expected two pages, got %v", queries)
+	}
+}
+
+func TestExporter_ExportJSONLines(t *testing.T) {
+	var queries []url.Values
+	table := testServer(t, &queries)
+
+	var buf bytes.Buffer
+	count, err := New(table, Options{
+		Format:       NDJSON,
+		View:         "Grid view",
+		Fields:       []string{"Name"},
+		Formula:      "NOT({Name} = '')",
+		StringFormat: true,
+		TimeZone:     "Europe/Moscow",
+		UserLocale:   "ru",
+	}).Export(context.Background(), &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Errorf("expected 2 records, got %d", count)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %d", len(lines))
+	}
+	var record airtable.Record
+	if err := json.Unmarshal([]byte(lines[1]), &record); err != nil {
+		t.Fatal(err)
+	}
+	if record.ID != "rec2" || record.CreatedTime == "" || record.Fields["Name"] != "Studio" {
+		t.Errorf("unexpected record %s", lines[1])
+	}
+
+	query := queries[0]
+	for key, value := range map[string]string{
+		"view":            "Grid view",
+		"fields[]":        "Name",
+		"filterByFormula": "NOT({Name} = '')",
+		"cellFormat":      "string",
+		"timeZone":        "Europe/Moscow",
+		"userLocale":      "ru",
+	} {
+		if query.Get(key) != value {
+			t.Errorf("expected %s=%q, got %q", key, value, query.Get(key))
+		}
+	}
+}
+
+func TestExporter_StringFormatDefaults(t *testing.T) {
+	var queries []url.Values
+	table := testServer(t, &queries)
+
+	if _, err := New(table, Options{Format: JSONLines, StringFormat: true}).Export(context.Background(), io.Discard); err != nil {
+		t.Fatal(err)
+	}
+	query := queries[0]
+	if query.Get("cellFormat") != "string" || query.Get("timeZone") != "UTC" || query.Get("userLocale") != "en-us" {
+		t.Errorf("time zone and locale should be defaulted, but query was: %v", query)
+	}
+}
+
+func TestFormatValue(t *testing.T) {
+	tests := []struct {
+		value    any
+		expected string
+	}{
+		{nil, ""},
+		{"text", "text"},
+		{float64(1234567.5), "1234567.5"},
+		{true, "true"},
+		{[]any{"a", "b"}, "a, b"},
+		{map[string]any{"id": "usr1", "email": "a@b.c", "name": "A"}, "a@b.c"},
+		{map[string]any{"text": "123", "type": "upce"}, "123"},
+		{map[string]any{"label": "Open", "url": "https://example.com"}, "https://example.com"},
+		{map[string]any{"state": "generated", "value": "summary"}, "summary"},
+	}
+	for _, tt := range tests {
+		if got := FormatValue(tt.value); got != tt.expected {
+			t.Errorf("FormatValue(%v) = %q, expected %q", tt.value, got, tt.expected)
+		}
+	}
+}
+
+func TestExporter_ExportXLSX(t *testing.T) {
+	var queries []url.Values
+	table := testServer(t, &queries)
+
+	var buf bytes.Buffer
+	count, err := New(table, Options{Format: XLSX}).Export(context.Background(), &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 2 {
+		t.Errorf("expected 2 records, got %d", count)
+	}
+
+	workbook, err := xlsx.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sheets := workbook.Sheets(); !reflect.DeepEqual(sheets, []string{"Apartments"}) {
+		t.Errorf("unexpected sheets %q", sheets)
+	}
+	rows, err := workbook.Rows("Apartments")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	expected := [][]string{
+		{"Name", "Pictures", "District"},
+		{"Loft, \"Downtown\"", "https://example.com/1.jpg, https://example.com/2.jpg", "recD1"},
+		{"Studio"},
+	}
+	for _, row := range expected {
+		got, err := rows.Read()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, row) {
+			t.Errorf("expected %q, got %q", row, got)
+		}
+	}
+}
+
+func TestCell(t *testing.T) {
+	dateField := &airtable.Field{Name: "Listed", Type: "date"}
+	dateTimeField := &airtable.Field{Name: "Visited", Type: "dateTime", Options: map[string]any{"timeZone": "Europe/Moscow"}}
+
+	var buf bytes.Buffer
+	workbook := xlsx.NewWriter(&buf)
+	sheet, err := workbook.NewSheet("Cells")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = sheet.WriteCells([]xlsx.Cell{
+		Cell(dateField, "2020-04-06"),
+		Cell(dateTimeField, "2020-04-06T06:00:00.000Z"),
+		Cell(nil, float64(12.5)),
+		Cell(nil, true),
+		Cell(&airtable.Field{Type: "url"}, "https://example.com"),
+		Cell(&airtable.Field{Type: "multipleSelects"}, []any{"a", "b"}),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := workbook.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	reader, err := xlsx.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err := reader.Rows("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	row, err := rows.Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"2020-04-06", "2020-04-06 09:00:00", "12.5", "true", "https://example.com", "a, b"}
+	if !reflect.DeepEqual(row, expected) {
+		t.Errorf("expected %q, got %q", expected, row)
+	}
+}
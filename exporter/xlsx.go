@@ -0,0 +1,133 @@
+// Copyright © 2020 Mike Berezin
+//
+// Use of this source code is governed by an MIT license.
+// Details in the LICENSE file.
+
+package exporter
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/mehanizm/airtable"
+	"github.com/mehanizm/airtable/xlsx"
+)
+
+// ExportXLSX exports tables to one workbook, a sheet per exporter
+// named after its table. Format and Checkpoint of the exporters options are ignored,
+// a workbook can not be appended to.
+// Returns number of exported records of all tables.
+func ExportXLSX(ctx context.Context, w io.Writer, exporters ...*Exporter) (int, error) {
+	workbook := xlsx.NewWriter(w)
+	total := 0
+	for _, e := range exporters {
+		schema, err := e.schema(ctx)
+		if err != nil {
+			return total, err
+		}
+		sheet, err := workbook.NewSheet(schema.Name)
+		if err != nil {
+			return total, err
+		}
+		columns := e.opts.Fields
+		if len(columns) == 0 {
+			columns = schemaColumns(schema)
+		}
+		fields := make([]*airtable.Field, len(columns))
+		for i, column := range columns {
+			if field := schema.Field(column); field != nil {
+				fields[i] = field.WithClientTimeZone(e.timeZone())
+			}
+		}
+		count, err := e.export(ctx, &xlsxWriter{
+			sheet:     sheet,
+			columns:   columns,
+			fields:    fields,
+			includeID: e.opts.IncludeID,
+		}, nil)
+		total += count
+		if err != nil {
+			return total, err
+		}
+	}
+	if err := workbook.Close(); err != nil {
+		return total, fmt.Errorf("cannot write workbook: %w", err)
+	}
+	return total, nil
+}
+
+type xlsxWriter struct {
+	sheet     *xlsx.Sheet
+	columns   []string
+	fields    []*airtable.Field
+	includeID bool
+}
+
+func (x *xlsxWriter) writeHeader() error {
+	header := x.columns
+	if x.includeID {
+		header = append([]string{"id"}, header...)
+	}
+	return x.sheet.Write(header)
+}
+
+func (x *xlsxWriter) write(record *airtable.Record) error {
+	cells := make([]xlsx.Cell, 0, len(x.columns)+1)
+	if x.includeID {
+		cells = append(cells, xlsx.String(record.ID))
+	}
+	for i, column := range x.columns {
+		cells = append(cells, Cell(x.fields[i], record.Fields[column]))
+	}
+	return x.sheet.WriteCells(cells)
+}
+
+func (x *xlsxWriter) flush() error {
+	return nil
+}
+
+// Cell converts cell value to typed XLSX cell: numbers, booleans,
+// dates of date fields in their time zone, attachments and URLs as hyperlinks
+// and other values as text formatted with FormatValue. field may be nil.
+func Cell(field *airtable.Field, value any) xlsx.Cell {
+	switch v := value.(type) {
+	case nil:
+		return xlsx.Cell{}
+	case float64:
+		return xlsx.Number(v)
+	case bool:
+		return xlsx.Bool(v)
+	}
+	if field == nil {
+		return xlsx.String(FormatValue(value))
+	}
+
+	if t, err := field.ToTime(value); err == nil {
+		if isDateOnly(field) {
+			return xlsx.Date(t)
+		}
+		return xlsx.DateTime(t)
+	}
+
+	switch field.Type {
+	case "multipleAttachments":
+		attachments, err := airtable.ToAttachments(value)
+		if err == nil && len(attachments) > 0 {
+			return xlsx.Link(FormatValue(value), attachments[0].URL)
+		}
+	case "url":
+		if s, ok := value.(string); ok {
+			return xlsx.Link(s, s)
+		}
+	}
+	return xlsx.String(FormatValue(value))
+}
+
+// isDateOnly reports whether date field or formula result has no time.
+func isDateOnly(field *airtable.Field) bool {
+	if result, ok := field.Options["result"].(map[string]any); ok {
+		return result["type"] == "date"
+	}
+	return field.Type == "date"
+}
@@ -6,7 +6,9 @@
 package airtable
 
 import (
+	"encoding/json"
 	"errors"
+	"math"
 	"time"
 )
 
@@ -14,16 +16,344 @@ const (
 	dateTimeFormat = "2006-01-02T15:04:05.000Z"
 )
 
-var ErrNotDateTime = errors.New("field is not date time")
+var (
+	ErrNotDateTime     = errors.New("field is not date time")
+	ErrNotString       = errors.New("field is not string")
+	ErrNotNumber       = errors.New("field is not number")
+	ErrNotInteger      = errors.New("field is not integer number")
+	ErrNotBool         = errors.New("field is not bool")
+	ErrNotDuration     = errors.New("field is not duration")
+	ErrNotStrings      = errors.New("field is not list of strings")
+	ErrNotCollaborator = errors.New("field is not collaborator")
+	ErrNotAttachments  = errors.New("field is not attachments")
+	ErrNotBarcode      = errors.New("field is not barcode")
+	ErrNotButton       = errors.New("field is not button")
+	ErrNotAIText       = errors.New("field is not AI text")
+)
+
+// Collaborator value of singleCollaborator, multipleCollaborators,
+// createdBy and lastModifiedBy fields.
+type Collaborator struct {
+	ID    string `json:"id,omitempty"`
+	Email string `json:"email,omitempty"`
+	Name  string `json:"name,omitempty"`
+}
+
+// Barcode value of barcode field.
+type Barcode struct {
+	Text string `json:"text,omitempty"`
+	Type string `json:"type,omitempty"`
+}
+
+// Button value of button field. Read only.
+type Button struct {
+	Label string `json:"label"`
+	URL   string `json:"url"`
+}
+
+// AIText value of aiText field. Read only.
+type AIText struct {
+	// State one of "empty", "loading", "generated" or "error".
+	State     string `json:"state"`
+	Value     string `json:"value"`
+	IsStale   bool   `json:"isStale"`
+	ErrorType string `json:"errorType,omitempty"`
+}
 
+// Converters below treat nil as an empty field,
+// because Airtable omits empty fields in responses,
+// and return zero value for it.
+
+// ToDateTime converts dateTime field with or without milliseconds.
+// Use Field.ToTime for date fields and field time zones.
 func ToDateTime(field any) (time.Time, error) {
-	fS, err := field.(string)
-	if !err {
+	if field == nil {
+		return time.Time{}, nil
+	}
+	fS, ok := field.(string)
+	if !ok {
 		return time.Time{}, ErrNotDateTime
 	}
-	return time.Parse(dateTimeFormat, fS)
+	return parseDateTime(fS)
 }
 
+// FromDateTime prepares time to write to dateTime field.
 func FromDateTime(t time.Time) any {
-	return t.Format(dateTimeFormat)
+	return t.UTC().Format(dateTimeFormat)
+}
+
+// ToString converts text, select, email, url and phone fields.
+func ToString(field any) (string, error) {
+	if field == nil {
+		return "", nil
+	}
+	s, ok := field.(string)
+	if !ok {
+		return "", ErrNotString
+	}
+	return s, nil
+}
+
+func FromString(s string) any {
+	return s
+}
+
+// ToFloat converts number, percent, currency, rating, count and autoNumber fields.
+func ToFloat(field any) (float64, error) {
+	switch f := field.(type) {
+	case nil:
+		return 0, nil
+	case float64:
+		return f, nil
+	case float32:
+		return float64(f), nil
+	case int:
+		return float64(f), nil
+	case int64:
+		return float64(f), nil
+	case json.Number:
+		v, err := f.Float64()
+		if err != nil {
+			return 0, ErrNotNumber
+		}
+		return v, nil
+	}
+	return 0, ErrNotNumber
+}
+
+func FromFloat(f float64) any {
+	return f
+}
+
+// ToInt converts number field without fractional part.
+// Airtable returns all numbers as float64.
+func ToInt(field any) (int, error) {
+	f, err := ToFloat(field)
+	if err != nil {
+		return 0, err
+	}
+	// float64(math.MaxInt) is rounded up to 1<<63 on 64-bit platforms
+	if f != math.Trunc(f) || f >= math.MaxInt+1 || f < math.MinInt {
+		return 0, ErrNotInteger
+	}
+	return int(f), nil
+}
+
+func FromInt(i int) any {
+	return i
+}
+
+// ToCurrency converts currency field.
+func ToCurrency(field any) (float64, error) {
+	return ToFloat(field)
+}
+
+func FromCurrency(f float64) any {
+	return f
+}
+
+// ToRating converts rating field.
+func ToRating(field any) (int, error) {
+	return ToInt(field)
+}
+
+func FromRating(rating int) any {
+	return rating
+}
+
+// ToBool converts checkbox field.
+// Unchecked checkbox is omitted by Airtable and converts to false.
+func ToBool(field any) (bool, error) {
+	if field == nil {
+		return false, nil
+	}
+	b, ok := field.(bool)
+	if !ok {
+		return false, ErrNotBool
+	}
+	return b, nil
+}
+
+func FromBool(b bool) any {
+	return b
+}
+
+// ToDuration converts duration field stored in seconds.
+func ToDuration(field any) (time.Duration, error) {
+	f, err := ToFloat(field)
+	if err != nil {
+		return 0, err
+	}
+	d := f * float64(time.Second)
+	if math.IsNaN(d) || d >= math.MaxInt64+1 || d < math.MinInt64 {
+		return 0, ErrNotDuration
+	}
+	return time.Duration(d), nil
+}
+
+func FromDuration(d time.Duration) any {
+	return d.Seconds()
+}
+
+// ToStrings converts multipleSelects and lookup of text fields.
+func ToStrings(field any) ([]string, error) {
+	switch f := field.(type) {
+	case nil:
+		return nil, nil
+	case []string:
+		return f, nil
+	case []any:
+		result := make([]string, 0, len(f))
+		for _, v := range f {
+			s, ok := v.(string)
+			if !ok {
+				return nil, ErrNotStrings
+			}
+			result = append(result, s)
+		}
+		return result, nil
+	}
+	return nil, ErrNotStrings
+}
+
+func FromStrings(values []string) any {
+	return values
+}
+
+// ToLinkedIDs converts multipleRecordLinks field to record IDs.
+func ToLinkedIDs(field any) ([]string, error) {
+	return ToStrings(field)
+}
+
+func FromLinkedIDs(recordIDs []string) any {
+	return recordIDs
+}
+
+// ToCollaborator converts singleCollaborator, createdBy and lastModifiedBy fields.
+func ToCollaborator(field any) (Collaborator, error) {
+	var c Collaborator
+	if field == nil {
+		return c, nil
+	}
+	if _, ok := field.(map[string]any); !ok {
+		return c, ErrNotCollaborator
+	}
+	err := convertJSON(field, &c, ErrNotCollaborator)
+	return c, err
+}
+
+// FromCollaborator prepares collaborator to write,
+// Airtable accepts ID or email.
+func FromCollaborator(c Collaborator) any {
+	if c.ID != "" {
+		return map[string]any{"id": c.ID}
+	}
+	return map[string]any{"email": c.Email}
+}
+
+// ToCollaborators converts multipleCollaborators field.
+func ToCollaborators(field any) ([]Collaborator, error) {
+	var c []Collaborator
+	if field == nil {
+		return c, nil
+	}
+	if _, ok := field.([]any); !ok {
+		return c, ErrNotCollaborator
+	}
+	err := convertJSON(field, &c, ErrNotCollaborator)
+	return c, err
+}
+
+func FromCollaborators(collaborators []Collaborator) any {
+	result := make([]any, 0, len(collaborators))
+	for _, c := range collaborators {
+		result = append(result, FromCollaborator(c))
+	}
+	return result
+}
+
+// ToAttachments converts multipleAttachments field.
+func ToAttachments(field any) ([]FieldAttachmentDetails, error) {
+	var a []FieldAttachmentDetails
+	if field == nil {
+		return a, nil
+	}
+	if _, ok := field.([]any); !ok {
+		return a, ErrNotAttachments
+	}
+	err := convertJSON(field, &a, ErrNotAttachments)
+	return a, err
+}
+
+// FromAttachments prepares attachments to write.
+// Existing attachments are referenced by ID, new ones by URL and file name.
+func FromAttachments(attachments []FieldAttachmentDetails) any {
+	result := make([]any, 0, len(attachments))
+	for _, a := range attachments {
+		if a.Id != "" {
+			result = append(result, map[string]any{"id": a.Id})
+			continue
+		}
+		attachment := map[string]any{"url": a.URL}
+		if a.FileName != "" {
+			attachment["filename"] = a.FileName
+		}
+		result = append(result, attachment)
+	}
+	return result
+}
+
+// ToBarcode converts barcode field.
+func ToBarcode(field any) (Barcode, error) {
+	var b Barcode
+	if field == nil {
+		return b, nil
+	}
+	if _, ok := field.(map[string]any); !ok {
+		return b, ErrNotBarcode
+	}
+	err := convertJSON(field, &b, ErrNotBarcode)
+	return b, err
+}
+
+func FromBarcode(b Barcode) any {
+	return map[string]any{"text": b.Text, "type": b.Type}
+}
+
+// ToButton converts button field.
+func ToButton(field any) (Button, error) {
+	var b Button
+	if field == nil {
+		return b, nil
+	}
+	if _, ok := field.(map[string]any); !ok {
+		return b, ErrNotButton
+	}
+	err := convertJSON(field, &b, ErrNotButton)
+	return b, err
+}
+
+// ToAIText converts aiText field.
+func ToAIText(field any) (AIText, error) {
+	var t AIText
+	if field == nil {
+		return t, nil
+	}
+	if _, ok := field.(map[string]any); !ok {
+		return t, ErrNotAIText
+	}
+	err := convertJSON(field, &t, ErrNotAIText)
+	return t, err
+}
+
+// convertJSON decodes field value to the target through JSON.
+func convertJSON(field, target any, errType error) error {
+	b, err := json.Marshal(field)
+	if err != nil {
+		return errType
+	}
+	if err := json.Unmarshal(b, target); err != nil {
+		return errType
+	}
+	return nil
 }
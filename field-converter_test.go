@@ -6,6 +6,8 @@
 package airtable
 
 import (
+	"encoding/json"
+	"errors"
 	"reflect"
 	"testing"
 	"time"
@@ -18,9 +20,11 @@ func TestToDateTime(t *testing.T) {
 		want    time.Time
 		wantErr bool
 	}{
+		{"nil", nil, time.Time{}, false},
 		{"not string", any(1), time.Time{}, true},
 		{"string not time", any("hello"), time.Time{}, true},
 		{"string time", any("2022-03-24T11:12:13.000Z"), time.Date(2022, 0o3, 24, 11, 12, 13, 0, time.UTC), false},
+		{"string time without millis", any("2022-03-24T11:12:13Z"), time.Date(2022, 0o3, 24, 11, 12, 13, 0, time.UTC), false},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -52,3 +56,183 @@ func TestFromDateTime(t *testing.T) {
 		})
 	}
 }
+
+func TestToNumbers(t *testing.T) {
+	tests := []struct {
+		name      string
+		field     any
+		wantFloat float64
+		wantInt   int
+		floatErr  error
+		intErr    error
+	}{
+		{"nil", nil, 0, 0, nil, nil},
+		{"float integer", any(float64(42)), 42, 42, nil, nil},
+		{"float fraction", any(4.5), 4.5, 0, nil, ErrNotInteger},
+		{"int", any(7), 7, 7, nil, nil},
+		{"json number", any(json.Number("3")), 3, 3, nil, nil},
+		{"string", any("3"), 0, 0, ErrNotNumber, ErrNotNumber},
+		{"int overflow", any(float64(1 << 63)), 1 << 63, 0, nil, ErrNotInteger},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotFloat, err := ToFloat(tt.field)
+			if !errors.Is(err, tt.floatErr) || gotFloat != tt.wantFloat {
+				t.Errorf("ToFloat() = %v, %v, want %v, %v", gotFloat, err, tt.wantFloat, tt.floatErr)
+			}
+			gotInt, err := ToInt(tt.field)
+			if !errors.Is(err, tt.intErr) || gotInt != tt.wantInt {
+				t.Errorf("ToInt() = %v, %v, want %v, %v", gotInt, err, tt.wantInt, tt.intErr)
+			}
+		})
+	}
+}
+
+func TestToBool(t *testing.T) {
+	tests := []struct {
+		name    string
+		field   any
+		want    bool
+		wantErr error
+	}{
+		{"absent", nil, false, nil},
+		{"checked", any(true), true, nil},
+		{"not bool", any("true"), false, ErrNotBool},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ToBool(tt.field)
+			if !errors.Is(err, tt.wantErr) || got != tt.want {
+				t.Errorf("ToBool() = %v, %v, want %v, %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestToString(t *testing.T) {
+	got, err := ToString(any("text"))
+	if err != nil || got != "text" {
+		t.Errorf("ToString() = %v, %v", got, err)
+	}
+	if _, err := ToString(any(1)); !errors.Is(err, ErrNotString) {
+		t.Errorf("ToString() error = %v, want %v", err, ErrNotString)
+	}
+}
+
+func TestToStrings(t *testing.T) {
+	tests := []struct {
+		name    string
+		field   any
+		want    []string
+		wantErr error
+	}{
+		{"absent", nil, nil, nil},
+		{"selects", any([]any{"a", "b"}), []string{"a", "b"}, nil},
+		{"strings", any([]string{"a"}), []string{"a"}, nil},
+		{"mixed", any([]any{"a", 1}), nil, ErrNotStrings},
+		{"string", any("a"), nil, ErrNotStrings},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ToStrings(tt.field)
+			if !errors.Is(err, tt.wantErr) || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ToStrings() = %v, %v, want %v, %v", got, err, tt.want, tt.wantErr)
+			}
+			got, err = ToLinkedIDs(tt.field)
+			if !errors.Is(err, tt.wantErr) || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ToLinkedIDs() = %v, %v, want %v, %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDuration(t *testing.T) {
+	got, err := ToDuration(any(float64(90.5)))
+	if err != nil || got != 90*time.Second+500*time.Millisecond {
+		t.Errorf("ToDuration() = %v, %v", got, err)
+	}
+	if from := FromDuration(got); from != any(90.5) {
+		t.Errorf("FromDuration() = %v, want 90.5", from)
+	}
+	if got, err := ToDuration(any(float64(1 << 40))); !errors.Is(err, ErrNotDuration) {
+		t.Errorf("ToDuration() of overflowing duration = %v, %v", got, err)
+	}
+}
+
+func TestToCollaborators(t *testing.T) {
+	field := any([]any{
+		map[string]any{"id": "usr1", "email": "a@example.com", "name": "A"},
+		map[string]any{"id": "usr2", "email": "b@example.com", "name": "B"},
+	})
+	got, err := ToCollaborators(field)
+	expected := []Collaborator{
+		{ID: "usr1", Email: "a@example.com", Name: "A"},
+		{ID: "usr2", Email: "b@example.com", Name: "B"},
+	}
+	if err != nil || !reflect.DeepEqual(got, expected) {
+		t.Errorf("ToCollaborators() = %v, %v, want %v", got, err, expected)
+	}
+	one, err := ToCollaborator(field.([]any)[0])
+	if err != nil || one != expected[0] {
+		t.Errorf("ToCollaborator() = %v, %v, want %v", one, err, expected[0])
+	}
+	if _, err := ToCollaborator(any("usr1")); !errors.Is(err, ErrNotCollaborator) {
+		t.Errorf("ToCollaborator() error = %v, want %v", err, ErrNotCollaborator)
+	}
+	from := FromCollaborators([]Collaborator{{ID: "usr1"}, {Email: "b@example.com"}})
+	expectedFrom := []any{map[string]any{"id": "usr1"}, map[string]any{"email": "b@example.com"}}
+	if !reflect.DeepEqual(from, expectedFrom) {
+		t.Errorf("FromCollaborators() = %v, want %v", from, expectedFrom)
+	}
+}
+
+func TestToAttachments(t *testing.T) {
+	field := any([]any{
+		map[string]any{
+			"id":       "att1",
+			"url":      "https://example.com/test.png",
+			"filename": "test.png",
+			"size":     float64(12345),
+			"type":     "image/png",
+		},
+	})
+	got, err := ToAttachments(field)
+	expected := []FieldAttachmentDetails{
+		{Id: "att1", URL: "https://example.com/test.png", FileName: "test.png", Size: 12345, Type: "image/png"},
+	}
+	if err != nil || !reflect.DeepEqual(got, expected) {
+		t.Errorf("ToAttachments() = %v, %v, want %v", got, err, expected)
+	}
+	if _, err := ToAttachments(any("att1")); !errors.Is(err, ErrNotAttachments) {
+		t.Errorf("ToAttachments() error = %v, want %v", err, ErrNotAttachments)
+	}
+	from := FromAttachments(append(got, FieldAttachmentDetails{URL: "https://example.com/new.png"}))
+	expectedFrom := []any{map[string]any{"id": "att1"}, map[string]any{"url": "https://example.com/new.png"}}
+	if !reflect.DeepEqual(from, expectedFrom) {
+		t.Errorf("FromAttachments() = %v, want %v", from, expectedFrom)
+	}
+}
+
+func TestToObjects(t *testing.T) {
+	barcode, err := ToBarcode(any(map[string]any{"text": "123", "type": "upce"}))
+	if err != nil || barcode != (Barcode{Text: "123", Type: "upce"}) {
+		t.Errorf("ToBarcode() = %v, %v", barcode, err)
+	}
+	button, err := ToButton(any(map[string]any{"label": "Open", "url": "https://example.com"}))
+	if err != nil || button != (Button{Label: "Open", URL: "https://example.com"}) {
+		t.Errorf("ToButton() = %v, %v", button, err)
+	}
+	aiText, err := ToAIText(any(map[string]any{"state": "generated", "value": "text", "isStale": true}))
+	if err != nil || aiText != (AIText{State: "generated", Value: "text", IsStale: true}) {
+		t.Errorf("ToAIText() = %v, %v", aiText, err)
+	}
+	if _, err := ToBarcode(any("123")); !errors.Is(err, ErrNotBarcode) {
+		t.Errorf("ToBarcode() error = %v, want %v", err, ErrNotBarcode)
+	}
+	if _, err := ToButton(any(map[string]any{"label": 1})); !errors.Is(err, ErrNotButton) {
+		t.Errorf("ToButton() error = %v, want %v", err, ErrNotButton)
+	}
+	if _, err := ToAIText(any(1)); !errors.Is(err, ErrNotAIText) {
+		t.Errorf("ToAIText() error = %v, want %v", err, ErrNotAIText)
+	}
+}
@@ -0,0 +1,273 @@
+// Copyright © 2020 Mike Berezin
+//
+// Use of this source code is governed by an MIT license.
+// Details in the LICENSE file.
+
+package airtable
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"strings"
+	"time"
+)
+
+const (
+	dateFormat = "2006-01-02"
+)
+
+var (
+	// ErrNotDateField returned when a date helper is used with a field of other type.
+	ErrNotDateField = errors.New("field type is not date or dateTime")
+	// ErrClientTimeZone returned when the field shows times in the time zone
+	// of the request, which is not known to the field, see WithClientTimeZone.
+	ErrClientTimeZone = errors.New(`field time zone is "client"`)
+)
+
+// Date civil date without time and time zone as stored in date fields.
+type Date struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+// DateOf returns the date of t in t's location.
+func DateOf(t time.Time) Date {
+	year, month, day := t.Date()
+	return Date{Year: year, Month: month, Day: day}
+}
+
+// ParseDate parses date in "2006-01-02" format.
+func ParseDate(s string) (Date, error) {
+	t, err := time.Parse(dateFormat, s)
+	if err != nil {
+		return Date{}, err
+	}
+	return DateOf(t), nil
+}
+
+// String returns date in "2006-01-02" format.
+func (d Date) String() string {
+	return fmt.Sprintf("%04d-%02d-%02d", d.Year, d.Month, d.Day)
+}
+
+// IsZero reports whether date is not set.
+func (d Date) IsZero() bool {
+	return d == Date{}
+}
+
+// In returns midnight of the date in loc.
+func (d Date) In(loc *time.Location) time.Time {
+	return time.Date(d.Year, d.Month, d.Day, 0, 0, 0, 0, loc)
+}
+
+// MarshalJSON encodes date in Airtable wire format.
+func (d Date) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON decodes date from Airtable wire format.
+func (d *Date) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	parsed, err := ParseDate(s)
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// ToDate converts date field.
+// Date time values are accepted and truncated to the UTC date.
+func ToDate(field any) (Date, error) {
+	if field == nil {
+		return Date{}, nil
+	}
+	s, ok := field.(string)
+	if !ok {
+		return Date{}, ErrNotDateTime
+	}
+	if d, err := ParseDate(s); err == nil {
+		return d, nil
+	}
+	t, err := parseDateTime(s)
+	if err != nil {
+		return Date{}, fmt.Errorf("%w: %q", ErrNotDateTime, s)
+	}
+	return DateOf(t.UTC()), nil
+}
+
+func FromDate(d Date) any {
+	return d.String()
+}
+
+// parseDateTime parses date time returned by Airtable
+// with or without milliseconds.
+func parseDateTime(s string) (time.Time, error) {
+	t, err := time.Parse(dateTimeFormat, s)
+	if err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339Nano, s)
+}
+
+// dateOptions reports whether the field holds date (not date time) values
+// and returns options describing its format.
+// Formula, rollup and lookup fields are described by their result type.
+func (f *Field) dateOptions() (isDate bool, options map[string]any, err error) {
+	fieldType, options := f.Type, f.Options
+	if result, ok := options["result"].(map[string]any); ok {
+		fieldType, _ = result["type"].(string)
+		options, _ = result["options"].(map[string]any)
+	}
+	switch fieldType {
+	case "date":
+		return true, options, nil
+	case "dateTime", "createdTime", "lastModifiedTime":
+		return false, options, nil
+	}
+	return false, nil, ErrNotDateField
+}
+
+// Location returns time zone of date field from its schema options,
+// UTC when it is not set. ErrClientTimeZone is returned for "client" time zone.
+func (f *Field) Location() (*time.Location, error) {
+	_, options, err := f.dateOptions()
+	if err != nil {
+		return nil, err
+	}
+	timeZone, _ := options["timeZone"].(string)
+	switch timeZone {
+	case "", "utc":
+		return time.UTC, nil
+	case "client":
+		return nil, fmt.Errorf("%w: field %q", ErrClientTimeZone, f.Name)
+	}
+	return time.LoadLocation(timeZone)
+}
+
+// WithClientTimeZone returns copy of the field with "client" time zone
+// replaced by timeZone, the time zone the records were requested in
+// with WithTimeZone or InStringFormat.
+func (f *Field) WithClientTimeZone(timeZone string) *Field {
+	field := *f
+	field.Options = withClientTimeZone(f.Options, timeZone)
+	if result, ok := f.Options["result"].(map[string]any); ok {
+		result = maps.Clone(result)
+		if options, ok := result["options"].(map[string]any); ok {
+			result["options"] = withClientTimeZone(options, timeZone)
+		}
+		field.Options["result"] = result
+	}
+	return &field
+}
+
+func withClientTimeZone(options map[string]any, timeZone string) map[string]any {
+	options = maps.Clone(options)
+	if options["timeZone"] == "client" {
+		options["timeZone"] = timeZone
+	}
+	return options
+}
+
+// TimeLayout returns Go layout of the field values
+// requested with InStringFormat, based on the schema date and time formats.
+func (f *Field) TimeLayout() (string, error) {
+	isDate, options, err := f.dateOptions()
+	if err != nil {
+		return "", err
+	}
+	layout := momentToLayout(formatOption(options, "dateFormat", "YYYY-MM-DD"))
+	if !isDate {
+		layout += " " + momentToLayout(formatOption(options, "timeFormat", "HH:mm"))
+	}
+	return layout, nil
+}
+
+// ToTime converts value of date or dateTime field to time
+// in the field's time zone.
+// Values in json cell format and in string cell format are accepted.
+func (f *Field) ToTime(field any) (time.Time, error) {
+	isDate, _, err := f.dateOptions()
+	if err != nil {
+		return time.Time{}, err
+	}
+	loc, err := f.Location()
+	if err != nil {
+		return time.Time{}, err
+	}
+	s, ok := field.(string)
+	if !ok {
+		return time.Time{}, ErrNotDateTime
+	}
+
+	if isDate {
+		if d, err := ParseDate(s); err == nil {
+			return d.In(loc), nil
+		}
+	} else if t, err := parseDateTime(s); err == nil {
+		return t.In(loc), nil
+	}
+
+	layout, err := f.TimeLayout()
+	if err != nil {
+		return time.Time{}, err
+	}
+	t, err := time.ParseInLocation(layout, s, loc)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%w: %q", ErrNotDateTime, s)
+	}
+	return t, nil
+}
+
+// FromTime prepares time to write to date or dateTime field.
+// Date fields receive the date of t in the field's time zone.
+func (f *Field) FromTime(t time.Time) (any, error) {
+	isDate, _, err := f.dateOptions()
+	if err != nil {
+		return nil, err
+	}
+	if !isDate {
+		return FromDateTime(t), nil
+	}
+	loc, err := f.Location()
+	if err != nil {
+		return nil, err
+	}
+	return FromDate(DateOf(t.In(loc))), nil
+}
+
+func formatOption(options map[string]any, key, defaultFormat string) string {
+	option, _ := options[key].(map[string]any)
+	format, _ := option["format"].(string)
+	if format == "" {
+		return defaultFormat
+	}
+	return format
+}
+
+// momentReplacer converts Airtable (moment.js) date format tokens to Go layout.
+var momentReplacer = strings.NewReplacer(
+	"YYYY", "2006",
+	"MM", "01",
+	"DD", "02",
+	"HH", "15",
+	"hh", "03",
+	"mm", "04",
+	"LL", "January 2, 2006",
+	"M", "1",
+	"D", "2",
+	"h", "3",
+	"a", "pm",
+	"A", "PM",
+	"l", "1/2/2006",
+)
+
+func momentToLayout(format string) string {
+	return momentReplacer.Replace(format)
+}
@@ -0,0 +1,164 @@
+// Copyright © 2020 Mike Berezin
+//
+// Use of this source code is governed by an MIT license.
+// Details in the LICENSE file.
+
+package airtable
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDate(t *testing.T) {
+	d, err := ParseDate("2022-03-04")
+	if err != nil || d != (Date{2022, time.March, 4}) {
+		t.Fatalf("ParseDate() = %v, %v", d, err)
+	}
+	if d.String() != "2022-03-04" {
+		t.Errorf("String() = %v", d.String())
+	}
+	b, err := json.Marshal(d)
+	if err != nil || string(b) != `"2022-03-04"` {
+		t.Errorf("MarshalJSON() = %s, %v", b, err)
+	}
+	var decoded Date
+	if err := json.Unmarshal(b, &decoded); err != nil || decoded != d {
+		t.Errorf("UnmarshalJSON() = %v, %v", decoded, err)
+	}
+	if !(Date{}).IsZero() || d.IsZero() {
+		t.Errorf("IsZero() is wrong")
+	}
+}
+
+func TestToDate(t *testing.T) {
+	tests := []struct {
+		name    string
+		field   any
+		want    Date
+		wantErr error
+	}{
+		{"absent", nil, Date{}, nil},
+		{"date", any("2022-03-24"), Date{2022, time.March, 24}, nil},
+		{"date time", any("2022-03-24T23:12:13.000Z"), Date{2022, time.March, 24}, nil},
+		{"not date", any("24.03.2022"), Date{}, ErrNotDateTime},
+		{"not string", any(1), Date{}, ErrNotDateTime},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ToDate(tt.field)
+			if !errors.Is(err, tt.wantErr) || got != tt.want {
+				t.Errorf("ToDate() = %v, %v, want %v, %v", got, err, tt.want, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestField_ToTime(t *testing.T) {
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	if err != nil {
+		t.Skip("no time zone data")
+	}
+	dateField := &Field{
+		Type: "date",
+		Options: map[string]any{
+			"dateFormat": map[string]any{"name": "us", "format": "M/D/YYYY"},
+		},
+	}
+	dateTimeField := &Field{
+		Type: "dateTime",
+		Options: map[string]any{
+			"dateFormat": map[string]any{"name": "european", "format": "D/M/YYYY"},
+			"timeFormat": map[string]any{"name": "12hour", "format": "h:mma"},
+			"timeZone":   "Europe/Moscow",
+		},
+	}
+	formulaField := &Field{
+		Type: "formula",
+		Options: map[string]any{
+			"result": map[string]any{"type": "date", "options": map[string]any{}},
+		},
+	}
+	tests := []struct {
+		name    string
+		f       *Field
+		field   any
+		want    time.Time
+		wantErr error
+	}{
+		{"date", dateField, any("2020-04-06"), time.Date(2020, 4, 6, 0, 0, 0, 0, time.UTC), nil},
+		{"date string format", dateField, any("4/6/2020"), time.Date(2020, 4, 6, 0, 0, 0, 0, time.UTC), nil},
+		{"date time", dateTimeField, any("2020-04-06T06:00:00.000Z"), time.Date(2020, 4, 6, 9, 0, 0, 0, moscow), nil},
+		{"date time no millis", dateTimeField, any("2020-04-06T06:00:00Z"), time.Date(2020, 4, 6, 9, 0, 0, 0, moscow), nil},
+		{"date time string format", dateTimeField, any("6/4/2020 9:00am"), time.Date(2020, 4, 6, 9, 0, 0, 0, moscow), nil},
+		{"formula date", formulaField, any("2020-04-06"), time.Date(2020, 4, 6, 0, 0, 0, 0, time.UTC), nil},
+		{"not date", dateField, any("hello"), time.Time{}, ErrNotDateTime},
+		{"not date field", &Field{Type: "number"}, any("2020-04-06"), time.Time{}, ErrNotDateField},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.f.ToTime(tt.field)
+			if !errors.Is(err, tt.wantErr) || !got.Equal(tt.want) {
+				t.Errorf("ToTime() = %v, %v, want %v, %v", got, err, tt.want, tt.wantErr)
+			}
+			if err == nil && got.Location().String() != tt.want.Location().String() {
+				t.Errorf("ToTime() location = %v, want %v", got.Location(), tt.want.Location())
+			}
+		})
+	}
+}
+
+func TestField_Location(t *testing.T) {
+	if _, err := time.LoadLocation("Europe/Moscow"); err != nil {
+		t.Skip("no time zone data")
+	}
+	field := &Field{Name: "Visited", Type: "dateTime", Options: map[string]any{"timeZone": "client"}}
+	if _, err := field.Location(); !errors.Is(err, ErrClientTimeZone) {
+		t.Errorf("should be ErrClientTimeZone, but was: %v", err)
+	}
+	loc, err := field.WithClientTimeZone("Europe/Moscow").Location()
+	if err != nil || loc.String() != "Europe/Moscow" {
+		t.Errorf("should be client time zone, but was: %v, %v", loc, err)
+	}
+	if field.Options["timeZone"] != "client" {
+		t.Errorf("field should not be changed, but was: %v", field.Options)
+	}
+
+	formula := &Field{Type: "formula", Options: map[string]any{
+		"result": map[string]any{"type": "dateTime", "options": map[string]any{"timeZone": "client"}},
+	}}
+	loc, err = formula.WithClientTimeZone("Europe/Moscow").Location()
+	if err != nil || loc.String() != "Europe/Moscow" {
+		t.Errorf("should be client time zone of formula result, but was: %v, %v", loc, err)
+	}
+	if loc, err := (&Field{Type: "date"}).Location(); err != nil || loc != time.UTC {
+		t.Errorf("should be UTC by default, but was: %v, %v", loc, err)
+	}
+}
+
+func TestField_FromTime(t *testing.T) {
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	if err != nil {
+		t.Skip("no time zone data")
+	}
+	value := time.Date(2020, 4, 6, 1, 0, 0, 0, moscow)
+
+	got, err := (&Field{Type: "date", Options: map[string]any{"timeZone": "Europe/Moscow"}}).FromTime(value)
+	if err != nil || got != any("2020-04-06") {
+		t.Errorf("FromTime() = %v, %v", got, err)
+	}
+	got, err = (&Field{Type: "date"}).FromTime(value)
+	if err != nil || got != any("2020-04-05") {
+		t.Errorf("FromTime() = %v, %v", got, err)
+	}
+	got, err = (&Field{Type: "dateTime"}).FromTime(value)
+	if err != nil || got != any("2020-04-05T22:00:00.000Z") {
+		t.Errorf("FromTime() = %v, %v", got, err)
+	}
+	_, err = (&Field{Type: "singleLineText"}).FromTime(value)
+	if !errors.Is(err, ErrNotDateField) {
+		t.Errorf("FromTime() error = %v, want %v", err, ErrNotDateField)
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright © 2020 Mike Berezin
+//
+// Use of this source code is governed by an MIT license.
+// Details in the LICENSE file.
+
+package airtable
+
+import (
+	"context"
+	"net/url"
+)
+
+// GetRecordConfig helper type to use in.
+// step by step get one record.
+type GetRecordConfig struct {
+	table    *Table
+	recordID string
+	params   url.Values
+}
+
+// GetRecordByID prepare step to get one record.
+func (t *Table) GetRecordByID(recordID string) *GetRecordConfig {
+	return &GetRecordConfig{
+		table:    t,
+		recordID: recordID,
+		params:   url.Values{},
+	}
+}
+
+// ReturnFieldsByFieldID return record fields keyed by field ID instead of field name.
+func (grc *GetRecordConfig) ReturnFieldsByFieldID() *GetRecordConfig {
+	grc.params.Set("returnFieldsByFieldId", "true")
+	return grc
+}
+
+// InStringFormat add parameter to get record in string format.
+// it require timezone
+// https://support.airtable.com/hc/en-us/articles/216141558-Supported-timezones-for-SET-TIMEZONE
+// and user locale data
+// https://support.airtable.com/hc/en-us/articles/220340268-Supported-locale-modifiers-for-SET-LOCALE
+func (grc *GetRecordConfig) InStringFormat(timeZone, userLocale string) *GetRecordConfig {
+	grc.params.Set("cellFormat", "string")
+	grc.params.Set("timeZone", timeZone)
+	grc.params.Set("userLocale", userLocale)
+	return grc
+}
+
+// InJSONFormat add parameter to get record in json cell format (default).
+func (grc *GetRecordConfig) InJSONFormat() *GetRecordConfig {
+	grc.params.Set("cellFormat", "json")
+	return grc
+}
+
+// WithTimeZone set the time zone used to format dates
+// when using string cell format.
+func (grc *GetRecordConfig) WithTimeZone(timeZone string) *GetRecordConfig {
+	grc.params.Set("timeZone", timeZone)
+	return grc
+}
+
+// WithUserLocale set the user locale used to format dates
+// when using string cell format.
+func (grc *GetRecordConfig) WithUserLocale(userLocale string) *GetRecordConfig {
+	grc.params.Set("userLocale", userLocale)
+	return grc
+}
+
+// Do send the prepared get record request.
+func (grc *GetRecordConfig) Do() (*Record, error) {
+	return grc.table.GetRecordWithParams(grc.recordID, grc.params)
+}
+
+// DoContext send the prepared get record request with context.
+func (grc *GetRecordConfig) DoContext(ctx context.Context) (*Record, error) {
+	return grc.table.GetRecordWithParamsContext(ctx, grc.recordID, grc.params)
+}
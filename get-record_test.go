@@ -0,0 +1,70 @@
+// Copyright © 2020 Mike Berezin
+//
+// Use of this source code is governed by an MIT license.
+// Details in the LICENSE file.
+
+package airtable
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestGetRecordConfig_Do(t *testing.T) {
+	mockData, err := os.ReadFile("testdata/get_record.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var gotQuery url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		gotQuery = r.URL.Query()
+		_, _ = rw.Write(mockData)
+	}))
+	defer server.Close()
+
+	table := testTable()
+	table.client.baseURL = server.URL
+
+	record, err := table.GetRecordByID("recnTq6CsvFM6vX2m").
+		ReturnFieldsByFieldID().
+		InStringFormat("Europe/Moscow", "ru").
+		Do()
+	if err != nil {
+		t.Fatalf("there should not be an err, but was: %v", err)
+	}
+	if record.ID != "recnTq6CsvFM6vX2m" || record.table != table {
+		t.Errorf("unexpected record: %#v", record)
+	}
+	expected := url.Values{
+		"returnFieldsByFieldId": {"true"},
+		"cellFormat":            {"string"},
+		"timeZone":              {"Europe/Moscow"},
+		"userLocale":            {"ru"},
+	}
+	if !reflect.DeepEqual(gotQuery, expected) {
+		t.Errorf("expected: %#v\nbut got: %#v", expected, gotQuery)
+	}
+
+	grc := table.GetRecordByID("recnTq6CsvFM6vX2m").
+		InJSONFormat().
+		WithTimeZone("UTC").
+		WithUserLocale("en")
+	expected = url.Values{
+		"cellFormat": {"json"},
+		"timeZone":   {"UTC"},
+		"userLocale": {"en"},
+	}
+	if !reflect.DeepEqual(grc.params, expected) {
+		t.Errorf("expected: %#v\nbut got: %#v", expected, grc.params)
+	}
+
+	table.client.baseURL = mockErrorResponse(404).URL
+	_, err = table.GetRecordByID("recnTq6CsvFM6vX2m").Do()
+	if err == nil {
+		t.Errorf("there should be an err, but was nil")
+	}
+}
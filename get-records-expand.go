@@ -0,0 +1,240 @@
+// Copyright © 2020 Mike Berezin
+//
+// Use of this source code is governed by an MIT license.
+// Details in the LICENSE file.
+
+package airtable
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// expandBatchSize number of record IDs in one RECORD_ID() formula.
+const expandBatchSize = 50
+
+// ErrExpandStringFormat returned when linked records are expanded with string cell format,
+// link fields have names of linked records instead of IDs then.
+var ErrExpandStringFormat = errors.New("linked records can not be expanded with string cell format")
+
+// expandConfig options of linked records expansion.
+type expandConfig struct {
+	fields []string
+	depth  int
+}
+
+// expandState state of linked records expansion
+// kept between pages of one iteration.
+type expandState struct {
+	*expandConfig
+	schema *Tables
+	// fetched linked records by ID.
+	fetched map[string]*Record
+	// expanded remaining depth the fetched records are expanded to by ID,
+	// a record reached again closer to the top is expanded deeper.
+	expanded map[string]int
+}
+
+// state returns new state of the expansion, nil if expansion is not configured.
+func (e *expandConfig) state() *expandState {
+	if e == nil {
+		return nil
+	}
+	return &expandState{expandConfig: e, fetched: map[string]*Record{}, expanded: map[string]int{}}
+}
+
+// Expand fetches records linked in multipleRecordLinks fields
+// and attaches them to Record.Linked. All link fields are expanded if no names passed.
+// Linked tables are found by the base schema, linked records are fetched
+// in batches and reused across pages of the same Iterate call.
+// Expansion fails with ErrExpandStringFormat with InStringFormat.
+func (grc *GetRecordsConfig) Expand(fieldNames ...string) *GetRecordsConfig {
+	if grc.expand == nil {
+		grc.expand = &expandConfig{depth: 1}
+	}
+	grc.expand.fields = append(grc.expand.fields, fieldNames...)
+	return grc
+}
+
+// ExpandDepth set how many levels of links to expand, 1 by default.
+// On deeper levels all link fields of linked records are expanded.
+func (grc *GetRecordsConfig) ExpandDepth(depth int) *GetRecordsConfig {
+	grc.Expand()
+	grc.expand.depth = depth
+	return grc
+}
+
+// expandLevel records of one table to expand.
+type expandLevel struct {
+	table   string
+	records []*Record
+	fields  []string
+}
+
+func (e *expandState) run(ctx context.Context, t *Table, records []*Record, params url.Values) error {
+	if e.schema == nil {
+		schema, err := t.client.GetBaseSchema(t.dbName).GetTablesContext(ctx)
+		if err != nil {
+			return fmt.Errorf("cannot get base schema: %w", err)
+		}
+		e.schema = schema
+	}
+
+	levels := []expandLevel{{table: t.tableName, records: records, fields: e.fields}}
+	for depth := 0; depth < e.depth && len(levels) > 0; depth++ {
+		var next []expandLevel
+		for _, level := range levels {
+			linked, err := e.expandLevel(ctx, t, level, params, e.depth-depth-1)
+			if err != nil {
+				return err
+			}
+			next = append(next, linked...)
+		}
+		levels = next
+	}
+
+	return nil
+}
+
+// expandLevel attaches linked records to the level records
+// and returns linked records to expand on the next level grouped by table,
+// remaining is the depth to expand the linked records to.
+func (e *expandState) expandLevel(ctx context.Context, t *Table, level expandLevel, params url.Values, remaining int) ([]expandLevel, error) {
+	schema := e.schema.Table(level.table)
+	if schema == nil {
+		return nil, fmt.Errorf("%w: %q", ErrTableNotFound, level.table)
+	}
+
+	linkFields := map[string]*Field{}
+	for _, field := range schema.Fields {
+		if field.Type == "multipleRecordLinks" {
+			linkFields[field.Name] = field
+			linkFields[field.ID] = field
+		}
+	}
+	var fields []*Field
+	if len(level.fields) == 0 {
+		for _, field := range schema.Fields {
+			if field.Type == "multipleRecordLinks" {
+				fields = append(fields, field)
+			}
+		}
+	}
+	for _, name := range level.fields {
+		field, ok := linkFields[name]
+		if !ok {
+			return nil, fmt.Errorf("field %q of table %q is not a linked record field", name, level.table)
+		}
+		fields = append(fields, field)
+	}
+
+	var next []expandLevel
+	for _, field := range fields {
+		linkedTableID, _ := field.Options["linkedTableId"].(string)
+		if linkedTableID == "" {
+			continue
+		}
+
+		var missing []string
+		var known []*Record
+		seen := map[string]bool{}
+		for _, record := range level.records {
+			ids, _ := ToLinkedIDs(record.Fields[fieldKey(record, field)])
+			for _, id := range ids {
+				if seen[id] {
+					continue
+				}
+				seen[id] = true
+				if linkedRecord, ok := e.fetched[id]; ok {
+					known = append(known, linkedRecord)
+				} else {
+					missing = append(missing, id)
+				}
+			}
+		}
+
+		fetched, err := e.fetch(ctx, t.client.GetTable(t.dbName, linkedTableID), missing, params)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, record := range level.records {
+			key := fieldKey(record, field)
+			ids, _ := ToLinkedIDs(record.Fields[key])
+			if len(ids) == 0 {
+				continue
+			}
+			if record.Linked == nil {
+				record.Linked = map[string][]*Record{}
+			}
+			linked := make([]*Record, 0, len(ids))
+			for _, id := range ids {
+				if linkedRecord, ok := e.fetched[id]; ok {
+					linked = append(linked, linkedRecord)
+				}
+			}
+			record.Linked[key] = linked
+		}
+
+		var expand []*Record
+		for _, linkedRecord := range append(known, fetched...) {
+			if depth, ok := e.expanded[linkedRecord.ID]; ok && depth >= remaining {
+				continue
+			}
+			e.expanded[linkedRecord.ID] = remaining
+			expand = append(expand, linkedRecord)
+		}
+		if remaining > 0 && len(expand) > 0 {
+			next = append(next, expandLevel{table: linkedTableID, records: expand})
+		}
+	}
+
+	return next, nil
+}
+
+// fetch gets records by IDs with RECORD_ID() formula.
+func (e *expandState) fetch(ctx context.Context, t *Table, recordIDs []string, params url.Values) ([]*Record, error) {
+	var result []*Record
+	for start := 0; start < len(recordIDs); start += expandBatchSize {
+		batch := recordIDs[start:min(start+expandBatchSize, len(recordIDs))]
+
+		conditions := make([]string, 0, len(batch))
+		for _, id := range batch {
+			conditions = append(conditions, fmt.Sprintf("RECORD_ID()='%s'", id))
+		}
+
+		batchParams := url.Values{}
+		batchParams.Set("filterByFormula", "OR("+strings.Join(conditions, ",")+")")
+		if params.Get("returnFieldsByFieldId") != "" {
+			batchParams.Set("returnFieldsByFieldId", params.Get("returnFieldsByFieldId"))
+		}
+
+		for {
+			records, err := t.GetRecordsWithParamsContext(ctx, batchParams)
+			if err != nil {
+				return nil, fmt.Errorf("cannot get linked records: %w", err)
+			}
+			for _, record := range records.Records {
+				e.fetched[record.ID] = record
+			}
+			result = append(result, records.Records...)
+			if records.Offset == "" {
+				break
+			}
+			batchParams.Set("offset", records.Offset)
+		}
+	}
+	return result, nil
+}
+
+// fieldKey returns key of the field in record fields,
+// which is field ID when requested with ReturnFieldsByFieldID.
+func fieldKey(record *Record, field *Field) string {
+	if _, ok := record.Fields[field.ID]; ok {
+		return field.ID
+	}
+	return field.Name
+}
@@ -0,0 +1,174 @@
+// Copyright © 2020 Mike Berezin
+//
+// Use of this source code is governed by an MIT license.
+// Details in the LICENSE file.
+
+package airtable
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+// mockExpandServer serves base schema and records of
+// Apartments and Districts tables and counts requests by path.
+// Requests with page size get two pages of the same records.
+func mockExpandServer(t *testing.T, requests map[string]int) *httptest.Server {
+	files := map[string]string{
+		"/meta/bases/dbName/tables": "testdata/base_schema.json",
+		"/dbName/Apartments":        "testdata/expand/apartments.json",
+		"/dbName/Unknown":           "testdata/expand/apartments.json",
+		"/dbName/tbltp8DGLhqbUmjK1": "testdata/expand/apartments.json",
+		"/dbName/tblK6MZHez0ZvBChZ": "testdata/expand/districts.json",
+	}
+	return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		requests[r.URL.Path]++
+		if strings.HasPrefix(r.URL.Path, "/dbName/tbl") &&
+			!strings.HasPrefix(r.URL.Query().Get("filterByFormula"), "OR(RECORD_ID()=") {
+			t.Errorf("linked records should be requested by RECORD_ID(), but was: %v", r.URL.Query())
+		}
+		mockData, err := os.ReadFile(files[r.URL.Path])
+		if err != nil {
+			http.Error(rw, err.Error(), http.StatusNotFound)
+			return
+		}
+		if r.URL.Query().Has("pageSize") && !r.URL.Query().Has("offset") {
+			page := map[string]any{}
+			_ = json.Unmarshal(mockData, &page)
+			page["offset"] = "page2"
+			mockData, _ = json.Marshal(page)
+		}
+		_, _ = rw.Write(mockData)
+	}))
+}
+
+func TestGetRecordsConfig_Expand(t *testing.T) {
+	requests := map[string]int{}
+	server := mockExpandServer(t, requests)
+	defer server.Close()
+
+	table := testClient().GetTable("dbName", "Apartments")
+	table.client.baseURL = server.URL
+
+	grc := table.GetRecords().PageSize(3).Expand("District")
+	var pages [][]*Record
+	for record, err := range grc.Iterate(context.Background()) {
+		if err != nil {
+			t.Fatalf("there should not be an err, but was: %v", err)
+		}
+		if len(pages) == 0 || len(pages[len(pages)-1]) == 3 {
+			pages = append(pages, nil)
+		}
+		pages[len(pages)-1] = append(pages[len(pages)-1], record)
+	}
+	if len(pages) != 2 {
+		t.Fatalf("expected 2 pages, but was: %d", len(pages))
+	}
+	for _, page := range pages {
+		records := &Records{Records: page}
+		district := records.Records[0].Linked["District"]
+		if len(district) != 1 || district[0].ID != "recDistrict1" {
+			t.Errorf("district should be expanded, but was: %v", records.Records[0].Linked)
+		}
+		if records.Records[1].Linked["District"][0] != district[0] {
+			t.Errorf("the same linked record should be shared")
+		}
+		if records.Records[2].Linked != nil {
+			t.Errorf("record without links should not be expanded, but was: %v", records.Records[2].Linked)
+		}
+		if district[0].Linked != nil {
+			t.Errorf("second level should not be expanded with depth 1")
+		}
+	}
+	if requests["/meta/bases/dbName/tables"] != 1 || requests["/dbName/tblK6MZHez0ZvBChZ"] != 1 {
+		t.Errorf("schema and linked records should be fetched once across pages, but was: %v", requests)
+	}
+	if _, err := grc.Do(); err != nil {
+		t.Fatalf("there should not be an err, but was: %v", err)
+	}
+	if requests["/dbName/tblK6MZHez0ZvBChZ"] != 2 {
+		t.Errorf("linked records should be fetched again by other request, but was: %v", requests)
+	}
+
+	records, err := table.GetRecords().ExpandDepth(2).Do()
+	if err != nil {
+		t.Fatalf("there should not be an err, but was: %v", err)
+	}
+	district := records.Records[0].Linked["District"][0]
+	if apartments := district.Linked["Apartments"]; len(apartments) != 2 || apartments[1].ID != "recApartment2" {
+		t.Errorf("second level should be expanded, but was: %v", district.Linked)
+	}
+
+	_, err = table.GetRecords().Expand("Name").Do()
+	if err == nil {
+		t.Errorf("there should be an err for not link field, but was nil")
+	}
+	unknown := table.client.GetTable("dbName", "Unknown")
+	_, err = unknown.GetRecords().Expand().Do()
+	if !errors.Is(err, ErrTableNotFound) {
+		t.Errorf("there should be an err for unknown table, but was nil")
+	}
+}
+
+func TestGetRecordsConfig_ExpandDiamond(t *testing.T) {
+	// recC is reached through recA on the first page and directly on the second one
+	links := map[string][]any{"recR1": {"recA"}, "recR2": {"recC"}, "recA": {"recC"}, "recC": {"recD"}}
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/meta/") {
+			_, _ = rw.Write([]byte(`{"tables": [{"id": "tblNodes", "name": "Nodes", "fields": [
+				{"id": "fldNext", "name": "Next", "type": "multipleRecordLinks", "options": {"linkedTableId": "tblNodes"}}]}]}`))
+			return
+		}
+		record := func(id string) *Record {
+			return &Record{ID: id, Fields: map[string]any{"Next": links[id]}}
+		}
+		records := &Records{}
+		switch formula := r.URL.Query().Get("filterByFormula"); {
+		case formula != "":
+			for id := range links {
+				if strings.Contains(formula, "'"+id+"'") {
+					records.Records = append(records.Records, record(id))
+				}
+			}
+			if strings.Contains(formula, "'recD'") {
+				records.Records = append(records.Records, &Record{ID: "recD", Fields: map[string]any{}})
+			}
+		case r.URL.Query().Get("offset") == "":
+			records.Records, records.Offset = []*Record{record("recR1")}, "page2"
+		default:
+			records.Records = []*Record{record("recR2")}
+		}
+		_ = json.NewEncoder(rw).Encode(records)
+	}))
+	defer server.Close()
+	table := testClient().GetTable("dbName", "Nodes")
+	table.client.baseURL = server.URL
+
+	var records []*Record
+	for record, err := range table.GetRecords().ExpandDepth(2).Iterate(context.Background()) {
+		if err != nil {
+			t.Fatalf("there should not be an err, but was: %v", err)
+		}
+		records = append(records, record)
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected 2 records, but was: %d", len(records))
+	}
+	c := records[1].Linked["Next"][0]
+	if next := c.Linked["Next"]; c.ID != "recC" || len(next) != 1 || next[0].ID != "recD" {
+		t.Errorf("record reached closer to the top should be expanded deeper, but was: %v", c.Linked)
+	}
+}
+
+func TestGetRecordsConfig_ExpandStringFormat(t *testing.T) {
+	_, err := testTable().GetRecords().Expand().InStringFormat("UTC", "en-us").Do()
+	if !errors.Is(err, ErrExpandStringFormat) {
+		t.Errorf("should be ErrExpandStringFormat, but was: %v", err)
+	}
+}
@@ -8,15 +8,26 @@ package airtable
 import (
 	"context"
 	"fmt"
+	"iter"
+	"maps"
 	"net/url"
 	"strconv"
+	"strings"
 )
 
+// RecordMetadata type of additional record metadata to return.
+type RecordMetadata string
+
+// RecordMetadataCommentCount return number of comments of each record in Record.CommentCount.
+const RecordMetadataCommentCount RecordMetadata = "commentCount"
+
 // GetRecordsConfig helper type to use in.
 // step by step get records.
 type GetRecordsConfig struct {
-	table  *Table
-	params url.Values
+	table   *Table
+	params  url.Values
+	usePost bool
+	expand  *expandConfig
 }
 
 // GetRecords prepare step to get records.
@@ -103,12 +114,162 @@ func (grc *GetRecordsConfig) InStringFormat(timeZone, userLocale string) *GetRec
 	return grc
 }
 
+// InJSONFormat add parameter to get records in json cell format (default).
+func (grc *GetRecordsConfig) InJSONFormat() *GetRecordsConfig {
+	grc.params.Set("cellFormat", "json")
+	return grc
+}
+
+// WithTimeZone set the time zone used to format dates
+// when using string cell format and in formulas.
+// https://support.airtable.com/hc/en-us/articles/216141558-Supported-timezones-for-SET-TIMEZONE
+func (grc *GetRecordsConfig) WithTimeZone(timeZone string) *GetRecordsConfig {
+	grc.params.Set("timeZone", timeZone)
+	return grc
+}
+
+// WithUserLocale set the user locale used to format dates
+// when using string cell format.
+// https://support.airtable.com/hc/en-us/articles/220340268-Supported-locale-modifiers-for-SET-LOCALE
+func (grc *GetRecordsConfig) WithUserLocale(userLocale string) *GetRecordsConfig {
+	grc.params.Set("userLocale", userLocale)
+	return grc
+}
+
+// ReturnFieldsByFieldID return record fields keyed by field ID instead of field name.
+func (grc *GetRecordsConfig) ReturnFieldsByFieldID() *GetRecordsConfig {
+	grc.params.Set("returnFieldsByFieldId", "true")
+	return grc
+}
+
+// WithRecordMetadata add additional metadata to returned records.
+func (grc *GetRecordsConfig) WithRecordMetadata(metadata ...RecordMetadata) *GetRecordsConfig {
+	for _, m := range metadata {
+		grc.params.Add("recordMetadata[]", string(m))
+	}
+	return grc
+}
+
+// UsePost send the request to the POST listRecords endpoint with JSON body
+// instead of GET with query parameters.
+// It is done automatically when the URL would exceed Airtable's length limit,
+// use it to force the POST request.
+// https://airtable.com/developers/web/api/list-records
+func (grc *GetRecordsConfig) UsePost() *GetRecordsConfig {
+	grc.usePost = true
+	return grc
+}
+
 // Do send the prepared get records request.
 func (grc *GetRecordsConfig) Do() (*Records, error) {
-	return grc.table.GetRecordsWithParams(grc.params)
+	return grc.DoContext(context.Background())
 }
 
 // DoContext send the prepared get records request with context.
 func (grc *GetRecordsConfig) DoContext(ctx context.Context) (*Records, error) {
-	return grc.table.GetRecordsWithParamsContext(ctx, grc.params)
+	return grc.do(ctx, grc.expand.state())
+}
+
+// do sends the request expanding linked records with the state,
+// which is shared by pages of one iteration.
+func (grc *GetRecordsConfig) do(ctx context.Context, expand *expandState) (*Records, error) {
+	if expand != nil && grc.params.Get("cellFormat") == "string" {
+		return nil, ErrExpandStringFormat
+	}
+	var records *Records
+	var err error
+	if grc.usePost {
+		records, err = grc.table.listRecordsContext(ctx, grc.params)
+	} else {
+		records, err = grc.table.GetRecordsWithParamsContext(ctx, grc.params)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if expand != nil {
+		err = expand.run(ctx, grc.table, records.Records, grc.params)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return records, nil
+}
+
+// Iterate walks all pages of the prepared request
+// starting from the configured offset, the config itself is not changed.
+// Iteration stops after the first error.
+func (grc *GetRecordsConfig) Iterate(ctx context.Context) iter.Seq2[*Record, error] {
+	return func(yield func(*Record, error) bool) {
+		page := *grc
+		page.params = maps.Clone(grc.params)
+		expand := grc.expand.state()
+		for {
+			records, err := page.do(ctx, expand)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			for _, record := range records.Records {
+				if !yield(record, nil) {
+					return
+				}
+			}
+			if records.Offset == "" {
+				return
+			}
+			page.params.Set("offset", records.Offset)
+		}
+	}
+}
+
+// listRecordsBody converts list records url params
+// to the JSON body of the POST listRecords request.
+func listRecordsBody(params url.Values) (map[string]any, error) {
+	body := make(map[string]any, len(params))
+	sort := []map[string]string{}
+
+	for key, values := range params {
+		if len(values) == 0 {
+			continue
+		}
+
+		var sortNum int
+		var sortKey string
+		if _, err := fmt.Sscanf(key, "sort[%d][%s", &sortNum, &sortKey); err == nil {
+			for len(sort) <= sortNum {
+				sort = append(sort, map[string]string{})
+			}
+			sort[sortNum][strings.TrimSuffix(sortKey, "]")] = values[0]
+			continue
+		}
+
+		switch key {
+		case "fields[]":
+			body["fields"] = values
+		case "recordMetadata[]":
+			body["recordMetadata"] = values
+		case "returnFieldsByFieldId":
+			value, err := strconv.ParseBool(values[0])
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s: %w", key, err)
+			}
+			body[key] = value
+		case "maxRecords", "pageSize":
+			value, err := strconv.Atoi(values[0])
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s: %w", key, err)
+			}
+			body[key] = value
+		default:
+			body[key] = values[0]
+		}
+	}
+
+	if len(sort) > 0 {
+		body["sort"] = sort
+	}
+
+	return body, nil
 }
@@ -6,6 +6,14 @@
 package airtable
 
 import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -44,3 +52,184 @@ func TestGetRecordsConfig_Do(t *testing.T) {
 		t.Errorf("there should be an err, but was nil")
 	}
 }
+
+func TestGetRecordsConfig_DoPost(t *testing.T) {
+	mockData, err := os.ReadFile("testdata/get_records_with_filter.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var gotMethod, gotPath string
+	var gotBody map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		gotMethod, gotPath = r.Method, r.URL.Path
+		gotBody = nil
+		_ = json.NewDecoder(r.Body).Decode(&gotBody)
+		_, _ = rw.Write(mockData)
+	}))
+	defer server.Close()
+
+	table := testTable()
+	table.client.baseURL = server.URL
+
+	records, err := table.GetRecords().
+		WithFilterFormula("{Field1}='value_1'").
+		PageSize(10).
+		UsePost().
+		Do()
+	if err != nil {
+		t.Fatalf("there should not be an err, but was: %v", err)
+	}
+	if len(records.Records) != 3 || records.Records[0].table != table {
+		t.Errorf("there should be 3 records bound to table, but was %v", records.Records)
+	}
+	if gotMethod != http.MethodPost || gotPath != "/dbName/tableName/listRecords" {
+		t.Errorf("expected POST to listRecords, but was %s %s", gotMethod, gotPath)
+	}
+	if gotBody["filterByFormula"] != "{Field1}='value_1'" || gotBody["pageSize"] != float64(10) {
+		t.Errorf("unexpected body: %v", gotBody)
+	}
+
+	longFormula := "OR(" + strings.Repeat("{Field1}='value_1',", 1000) + "FALSE())"
+	_, err = table.GetRecords().WithFilterFormula(longFormula).Do()
+	if err != nil {
+		t.Fatalf("there should not be an err, but was: %v", err)
+	}
+	if gotMethod != http.MethodPost || gotBody["filterByFormula"] != longFormula {
+		t.Errorf("long request should be sent with POST, but was %s", gotMethod)
+	}
+
+	_, err = table.GetRecords().WithFilterFormula("TRUE()").Do()
+	if err != nil {
+		t.Fatalf("there should not be an err, but was: %v", err)
+	}
+	if gotMethod != http.MethodGet {
+		t.Errorf("short request should be sent with GET, but was %s", gotMethod)
+	}
+}
+
+func TestGetRecordsConfig_Options(t *testing.T) {
+	table := testTable()
+	table.client.baseURL = mockResponse("get_records_with_metadata.json").URL
+
+	grc := table.GetRecords().
+		InJSONFormat().
+		WithTimeZone("Europe/Moscow").
+		WithUserLocale("ru").
+		ReturnFieldsByFieldID().
+		WithRecordMetadata(RecordMetadataCommentCount)
+	expected := url.Values{
+		"cellFormat":            {"json"},
+		"timeZone":              {"Europe/Moscow"},
+		"userLocale":            {"ru"},
+		"returnFieldsByFieldId": {"true"},
+		"recordMetadata[]":      {"commentCount"},
+	}
+	if !reflect.DeepEqual(grc.params, expected) {
+		t.Errorf("expected: %#v\nbut got: %#v", expected, grc.params)
+	}
+
+	records, err := grc.Do()
+	if err != nil {
+		t.Fatalf("there should not be an err, but was: %v", err)
+	}
+	if records.Records[0].CommentCount != 2 {
+		t.Errorf("comment count should be 2, but was %v", records.Records[0].CommentCount)
+	}
+	if records.Records[0].Fields["fldEVzvQOoULO38yl"] != "Field1" {
+		t.Errorf("fields should be keyed by id, but was %v", records.Records[0].Fields)
+	}
+}
+
+func Test_listRecordsBody(t *testing.T) {
+	params := url.Values{}
+	params.Add("fields[]", "Field1")
+	params.Add("fields[]", "Field2")
+	params.Set("sort[0][field]", "Field1")
+	params.Set("sort[0][direction]", "desc")
+	params.Set("sort[1][field]", "Field2")
+	params.Set("maxRecords", "100")
+	params.Set("view", "view_1")
+	params.Set("offset", "itr1/rec1")
+	params.Set("returnFieldsByFieldId", "true")
+	params.Add("recordMetadata[]", "commentCount")
+
+	got, err := listRecordsBody(params)
+	if err != nil {
+		t.Fatalf("there should not be an err, but was: %v", err)
+	}
+	expected := map[string]any{
+		"fields": []string{"Field1", "Field2"},
+		"sort": []map[string]string{
+			{"field": "Field1", "direction": "desc"},
+			{"field": "Field2"},
+		},
+		"maxRecords": 100,
+		"view":       "view_1",
+		"offset":     "itr1/rec1",
+
+		"returnFieldsByFieldId": true,
+		"recordMetadata":        []string{"commentCount"},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected: %#v\nbut got: %#v", expected, got)
+	}
+
+	_, err = listRecordsBody(url.Values{"pageSize": {"ten"}})
+	if err == nil {
+		t.Errorf("there should be an err, but was nil")
+	}
+	_, err = listRecordsBody(url.Values{"returnFieldsByFieldId": {"yes"}})
+	if err == nil {
+		t.Errorf("there should be an err, but was nil")
+	}
+}
+
+func TestGetRecordsConfig_Iterate(t *testing.T) {
+	var offsets []string
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		offset := r.URL.Query().Get("offset")
+		offsets = append(offsets, offset)
+		switch offset {
+		case "":
+			_, _ = rw.Write([]byte(`{"records": [{"id": "rec1"}, {"id": "rec2"}], "offset": "itr/rec2"}`))
+		case "itr/rec2":
+			_, _ = rw.Write([]byte(`{"records": [{"id": "rec3"}]}`))
+		default:
+			http.Error(rw, "unknown offset", http.StatusUnprocessableEntity)
+		}
+	}))
+	defer server.Close()
+
+	table := testTable()
+	table.client.baseURL = server.URL
+
+	grc := table.GetRecords().PageSize(2)
+	var ids []string
+	for record, err := range grc.Iterate(context.Background()) {
+		if err != nil {
+			t.Fatalf("there should not be an err, but was: %v", err)
+		}
+		ids = append(ids, record.ID)
+	}
+	if !reflect.DeepEqual(ids, []string{"rec1", "rec2", "rec3"}) {
+		t.Errorf("all pages should be iterated, but was: %v", ids)
+	}
+	if grc.params.Get("offset") != "" {
+		t.Errorf("config should not be changed, but offset was: %v", grc.params.Get("offset"))
+	}
+
+	for range grc.Iterate(context.Background()) {
+		break
+	}
+	if len(offsets) != 3 {
+		t.Errorf("iteration should stop on break, but requested offsets were: %v", offsets)
+	}
+
+	var gotErr error
+	for _, err := range table.GetRecords().WithOffset("bad").Iterate(context.Background()) {
+		gotErr = err
+	}
+	if gotErr == nil {
+		t.Errorf("there should be an err, but was nil")
+	}
+}
@@ -1,5 +1,8 @@
 module github.com/mehanizm/airtable
 
-go 1.23
+go 1.23.0
 
-require golang.org/x/time v0.8.0
+require (
+	go.uber.org/mock v0.6.0
+	golang.org/x/time v0.8.0
+)
@@ -0,0 +1,198 @@
+// Copyright © 2020 Mike Berezin
+//
+// Use of this source code is governed by an MIT license.
+// Details in the LICENSE file.
+
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mehanizm/airtable"
+)
+
+// ErrComputedField returned for fields which values can not be written.
+var ErrComputedField = errors.New("field is computed and can not be written")
+
+// computedTypes field types calculated by Airtable.
+var computedTypes = map[string]bool{
+	"formula":              true,
+	"rollup":               true,
+	"count":                true,
+	"lookup":               true,
+	"multipleLookupValues": true,
+	"autoNumber":           true,
+	"createdTime":          true,
+	"lastModifiedTime":     true,
+	"createdBy":            true,
+	"lastModifiedBy":       true,
+	"button":               true,
+	"aiText":               true,
+}
+
+// dateTimeLayouts accepted for dateTime fields in addition to the schema format.
+var dateTimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+}
+
+// thousandsRegexp number with commas between groups of thousands.
+var thousandsRegexp = regexp.MustCompile(`^[+-]?\d{1,3}(,\d{3})+(\.\d*)?$`)
+
+// Coerce converts text value to the value of the field type
+// to write with AddRecords. Empty value returns nil.
+// Percent values are fractions as stored by Airtable, or percents with "%" sign.
+func Coerce(field *airtable.Field, value string) (any, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	if computedTypes[field.Type] {
+		return nil, ErrComputedField
+	}
+
+	switch field.Type {
+	case "number", "currency":
+		return parseNumber(field, value)
+	case "percent":
+		// Airtable stores 50% as 0.5, like the exporter writes it
+		if strings.HasSuffix(value, "%") {
+			f, err := parseNumber(field, strings.TrimSuffix(value, "%"))
+			if err != nil {
+				return nil, err
+			}
+			return f / 100, nil
+		}
+		return parseNumber(field, value)
+	case "rating":
+		return strconv.Atoi(value)
+	case "duration":
+		return parseDuration(value)
+	case "checkbox":
+		return parseBool(value)
+	case "multipleSelects":
+		return splitList(value), nil
+	case "multipleRecordLinks":
+		ids := splitList(value)
+		for _, id := range ids {
+			if !strings.HasPrefix(id, "rec") {
+				return nil, fmt.Errorf("%q is not a record ID, use typecast to link by primary field", id)
+			}
+		}
+		return ids, nil
+	case "multipleAttachments":
+		var attachments []airtable.FieldAttachmentDetails
+		for _, url := range splitList(value) {
+			attachments = append(attachments, airtable.FieldAttachmentDetails{URL: url})
+		}
+		return airtable.FromAttachments(attachments), nil
+	case "singleCollaborator":
+		return airtable.FromCollaborator(collaborator(value)), nil
+	case "multipleCollaborators":
+		var collaborators []airtable.Collaborator
+		for _, item := range splitList(value) {
+			collaborators = append(collaborators, collaborator(item))
+		}
+		return airtable.FromCollaborators(collaborators), nil
+	case "barcode":
+		return map[string]any{"text": value}, nil
+	case "date", "dateTime":
+		return parseDate(field, value)
+	}
+
+	return value, nil
+}
+
+func parseNumber(field *airtable.Field, value string) (float64, error) {
+	if symbol, ok := field.Options["symbol"].(string); ok && symbol != "" {
+		value = strings.TrimSpace(strings.TrimPrefix(value, symbol))
+	}
+	number := value
+	if strings.Contains(number, ",") {
+		// only thousands separators, "1,5" may be a decimal comma
+		if !thousandsRegexp.MatchString(number) {
+			return 0, fmt.Errorf("%q is not a number, commas are allowed only between thousands", value)
+		}
+		number = strings.ReplaceAll(number, ",", "")
+	}
+	f, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a number", value)
+	}
+	return f, nil
+}
+
+// parseDuration parses seconds or h:mm[:ss] duration to seconds.
+func parseDuration(value string) (float64, error) {
+	if f, err := strconv.ParseFloat(value, 64); err == nil {
+		return f, nil
+	}
+	parts := strings.Split(value, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, fmt.Errorf("%q is not a duration", value)
+	}
+	var seconds float64
+	for _, part := range parts {
+		f, err := strconv.ParseFloat(part, 64)
+		if err != nil {
+			return 0, fmt.Errorf("%q is not a duration", value)
+		}
+		seconds = seconds*60 + f
+	}
+	if len(parts) == 2 {
+		seconds *= 60
+	}
+	return seconds, nil
+}
+
+func parseBool(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "true", "yes", "y", "1", "x", "checked", "on":
+		return true, nil
+	case "false", "no", "n", "0", "unchecked", "off":
+		return false, nil
+	}
+	return false, fmt.Errorf("%q is not a checkbox value", value)
+}
+
+func parseDate(field *airtable.Field, value string) (any, error) {
+	if t, err := field.ToTime(value); err == nil {
+		return field.FromTime(t)
+	}
+	if field.Type == "dateTime" {
+		loc, err := field.Location()
+		if err != nil {
+			return nil, err
+		}
+		for _, layout := range dateTimeLayouts {
+			if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+				return field.FromTime(t)
+			}
+		}
+	}
+	return nil, fmt.Errorf("%q is not a date", value)
+}
+
+func collaborator(value string) airtable.Collaborator {
+	if strings.Contains(value, "@") {
+		return airtable.Collaborator{Email: value}
+	}
+	return airtable.Collaborator{ID: value}
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
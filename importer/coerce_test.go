@@ -0,0 +1,109 @@
+// Copyright © 2020 Mike Berezin
+//
+// Use of this source code is governed by an MIT license.
+// Details in the LICENSE file.
+
+package importer
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/mehanizm/airtable"
+	"github.com/mehanizm/airtable/exporter"
+	"github.com/mehanizm/airtable/xlsx"
+)
+
+func TestCoerce(t *testing.T) {
+	tests := []struct {
+		name    string
+		field   *airtable.Field
+		value   string
+		want    any
+		wantErr bool
+	}{
+		{"empty", &airtable.Field{Type: "number"}, " ", nil, false},
+		{"text", &airtable.Field{Type: "multilineText"}, "text", "text", false},
+		{"number", &airtable.Field{Type: "number"}, "1,234.5", 1234.5, false},
+		{"not number", &airtable.Field{Type: "number"}, "many", nil, true},
+		{"decimal comma", &airtable.Field{Type: "number"}, "1,5", nil, true},
+		{"misplaced comma", &airtable.Field{Type: "number"}, "12,34,567", nil, true},
+		{"thousands", &airtable.Field{Type: "number"}, "-1,234,567", float64(-1234567), false},
+		{"currency", &airtable.Field{Type: "currency", Options: map[string]any{"symbol": "€"}}, "€ 10", float64(10), false},
+		{"percent", &airtable.Field{Type: "percent"}, "50%", 0.5, false},
+		{"percent fraction", &airtable.Field{Type: "percent"}, "0.25", 0.25, false},
+		{"rating", &airtable.Field{Type: "rating"}, "4", 4, false},
+		{"duration seconds", &airtable.Field{Type: "duration"}, "90", float64(90), false},
+		{"duration h:mm", &airtable.Field{Type: "duration"}, "1:30", float64(5400), false},
+		{"duration h:mm:ss", &airtable.Field{Type: "duration"}, "0:01:30", float64(90), false},
+		{"not duration", &airtable.Field{Type: "duration"}, "1:a", nil, true},
+		{"checkbox", &airtable.Field{Type: "checkbox"}, "Yes", true, false},
+		{"unchecked", &airtable.Field{Type: "checkbox"}, "0", false, false},
+		{"not checkbox", &airtable.Field{Type: "checkbox"}, "maybe", nil, true},
+		{"selects", &airtable.Field{Type: "multipleSelects"}, "a, b,", []string{"a", "b"}, false},
+		{"links", &airtable.Field{Type: "multipleRecordLinks"}, "rec1,rec2", []string{"rec1", "rec2"}, false},
+		{"links by name", &airtable.Field{Type: "multipleRecordLinks"}, "District", nil, true},
+		{"attachments", &airtable.Field{Type: "multipleAttachments"}, "https://example.com/1.png", []any{map[string]any{"url": "https://example.com/1.png"}}, false},
+		{"collaborator", &airtable.Field{Type: "singleCollaborator"}, "a@example.com", map[string]any{"email": "a@example.com"}, false},
+		{"collaborators", &airtable.Field{Type: "multipleCollaborators"}, "usr1, b@example.com", []any{map[string]any{"id": "usr1"}, map[string]any{"email": "b@example.com"}}, false},
+		{"barcode", &airtable.Field{Type: "barcode"}, "123", map[string]any{"text": "123"}, false},
+		{"date", &airtable.Field{Type: "date"}, "2024-05-01", "2024-05-01", false},
+		{"date time", &airtable.Field{Type: "dateTime"}, "2024-05-01 10:30", "2024-05-01T10:30:00.000Z", false},
+		{"date time iso", &airtable.Field{Type: "dateTime"}, "2024-05-01T10:30:00+02:00", "2024-05-01T08:30:00.000Z", false},
+		{"not date", &airtable.Field{Type: "date"}, "tomorrow", nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Coerce(tt.field, tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Coerce() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Coerce() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := Coerce(&airtable.Field{Type: "formula"}, "1"); !errors.Is(err, ErrComputedField) {
+		t.Errorf("should be ErrComputedField, but was: %v", err)
+	}
+}
+
+func TestCoerce_PercentRoundTrip(t *testing.T) {
+	field := &airtable.Field{Type: "percent", Options: map[string]any{"precision": 1}}
+
+	if got, err := Coerce(field, exporter.FormatValue(0.5)); err != nil || got != 0.5 {
+		t.Errorf("CSV value should import as exported, but was: %v, %v", got, err)
+	}
+
+	var b bytes.Buffer
+	workbook := xlsx.NewWriter(&b)
+	sheet, err := workbook.NewSheet("Sheet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sheet.WriteCells([]xlsx.Cell{exporter.Cell(field, 0.5)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := workbook.Close(); err != nil {
+		t.Fatal(err)
+	}
+	reader, err := xlsx.NewReader(bytes.NewReader(b.Bytes()), int64(b.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err := reader.Rows("Sheet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	row, err := rows.Read()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := Coerce(field, row[0]); err != nil || got != 0.5 {
+		t.Errorf("XLSX value should import as exported, but was: %v, %v", got, err)
+	}
+}
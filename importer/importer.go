@@ -0,0 +1,329 @@
+// Copyright © 2020 Mike Berezin
+//
+// Use of this source code is governed by an MIT license.
+// Details in the LICENSE file.
+
+// Package importer loads rows of CSV and other tabular files into an Airtable table.
+//
+// Columns are mapped to fields by header or mapping, values are coerced
+// by the field types from the base schema or sent as text with typecast.
+// Rows which can not be converted or written are written to rejects with the error.
+package importer
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/mehanizm/airtable"
+)
+
+// RowReader source of rows, the first row is the header.
+// Read returns io.EOF after the last row. *csv.Reader satisfies it.
+type RowReader interface {
+	Read() ([]string, error)
+}
+
+// fieldPositioner reports the line of the field of the last read row,
+// for rows spanning several lines. *csv.Reader satisfies it.
+type fieldPositioner interface {
+	FieldPos(field int) (line, column int)
+}
+
+// RowWriter destination of rejected rows. *csv.Writer satisfies it.
+type RowWriter interface {
+	Write(row []string) error
+}
+
+// Options of the import.
+type Options struct {
+	// Mapping of column names to field names or IDs.
+	// If empty, columns are mapped to fields with the same name.
+	// Columns not in the mapping are skipped.
+	Mapping map[string]string
+	// Typecast sends text values and lets Airtable convert them
+	// instead of coercing them by the schema field types.
+	Typecast bool
+	// MergeOn fields to upsert records on, records are only created if empty.
+	MergeOn []string
+	// TimeZone of the values of date fields with "client" time zone,
+	// values of such fields are rejected if it is empty.
+	TimeZone string
+}
+
+// Result of the import.
+type Result struct {
+	Created  int
+	Updated  int
+	Rejected int
+}
+
+// Importer writes rows to the table.
+type Importer struct {
+	table  airtable.TableAPI
+	schema *airtable.TableSchema
+	opts   Options
+}
+
+// New creates importer to the table with its schema from GetBaseSchema.
+func New(table airtable.TableAPI, schema *airtable.TableSchema, opts Options) *Importer {
+	return &Importer{
+		table:  table,
+		schema: schema,
+		opts:   opts,
+	}
+}
+
+// column mapped column of the input.
+type column struct {
+	index int
+	field *airtable.Field
+}
+
+// row input row waiting to be written.
+type row struct {
+	line   int
+	values []string
+	record *airtable.Record
+}
+
+// ImportCSV imports CSV with header and writes rejected rows as CSV
+// with an additional error column. rejects may be nil.
+func (im *Importer) ImportCSV(ctx context.Context, r io.Reader, rejects io.Writer) (*Result, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	var writer *csv.Writer
+	var rowWriter RowWriter
+	if rejects != nil {
+		writer = csv.NewWriter(rejects)
+		rowWriter = writer
+	}
+
+	result, err := im.Import(ctx, reader, rowWriter)
+	if writer != nil {
+		writer.Flush()
+		if flushErr := writer.Error(); err == nil && flushErr != nil {
+			err = fmt.Errorf("cannot write rejects: %w", flushErr)
+		}
+	}
+	return result, err
+}
+
+// Import reads rows and writes them to the table in batches of 10.
+// If Airtable rejects a batch, its rows are written one by one to reject only invalid rows.
+// Rejected rows are written to rejects with an additional error column, rejects may be nil.
+// The returned error is only for failures of the whole import,
+// like unreadable input, unknown columns or failed requests.
+func (im *Importer) Import(ctx context.Context, rows RowReader, rejects RowWriter) (*Result, error) {
+	header, err := rows.Read()
+	if errors.Is(err, io.EOF) {
+		return &Result{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot read header: %w", err)
+	}
+
+	columns, err := im.columns(header)
+	if err != nil {
+		return nil, err
+	}
+
+	if rejects != nil {
+		if err := rejects.Write(append(append([]string{}, header...), "error")); err != nil {
+			return nil, fmt.Errorf("cannot write rejects: %w", err)
+		}
+	}
+
+	result := &Result{}
+	reject := func(values []string, rowErr error) error {
+		result.Rejected++
+		if rejects == nil {
+			return nil
+		}
+		// short rows are padded to keep the error column aligned with the header
+		row := append(make([]string, 0, len(header)+1), values...)
+		for len(row) < len(header) {
+			row = append(row, "")
+		}
+		if err := rejects.Write(append(row, rowErr.Error())); err != nil {
+			return fmt.Errorf("cannot write rejects: %w", err)
+		}
+		return nil
+	}
+
+	var batch []row
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		err := im.writeBatch(ctx, batch, result, reject)
+		batch = batch[:0]
+		if err != nil {
+			return err
+		}
+		return ctx.Err()
+	}
+
+	positioner, _ := rows.(fieldPositioner)
+	for line := 2; ; line++ {
+		values, err := rows.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				if err := reject(values, fmt.Errorf("row %d: %w", parseErr.StartLine, err)); err != nil {
+					return result, err
+				}
+				continue
+			}
+			return result, fmt.Errorf("cannot read row %d: %w", line, err)
+		}
+		if positioner != nil {
+			line, _ = positioner.FieldPos(0)
+		}
+
+		record, err := im.record(columns, values)
+		if err != nil {
+			if err := reject(values, fmt.Errorf("row %d: %w", line, err)); err != nil {
+				return result, err
+			}
+			continue
+		}
+
+		batch = append(batch, row{line: line, values: values, record: record})
+		if len(batch) == airtable.MaxBatchSize {
+			if err := flush(); err != nil {
+				return result, err
+			}
+		}
+	}
+
+	if err := flush(); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// columns maps header to fields.
+func (im *Importer) columns(header []string) ([]column, error) {
+	var columns []column
+	for i, name := range header {
+		fieldName := name
+		if len(im.opts.Mapping) > 0 {
+			var ok bool
+			if fieldName, ok = im.opts.Mapping[name]; !ok {
+				continue
+			}
+		}
+
+		field := &airtable.Field{Name: fieldName, Type: "singleLineText"}
+		if im.schema != nil {
+			if field = im.schema.Field(fieldName); field == nil {
+				return nil, fmt.Errorf("column %q: field %q not found in table %q", name, fieldName, im.schema.Name)
+			}
+			if computedTypes[field.Type] {
+				return nil, fmt.Errorf("column %q: field %q: %w", name, fieldName, ErrComputedField)
+			}
+			if im.opts.TimeZone != "" {
+				field = field.WithClientTimeZone(im.opts.TimeZone)
+			}
+		}
+		columns = append(columns, column{index: i, field: field})
+	}
+	if len(columns) == 0 {
+		return nil, errors.New("no columns mapped to fields")
+	}
+	return columns, nil
+}
+
+// record converts row values to record fields.
+func (im *Importer) record(columns []column, values []string) (*airtable.Record, error) {
+	fields := make(map[string]any, len(columns))
+	for _, c := range columns {
+		if c.index >= len(values) {
+			continue
+		}
+		if im.opts.Typecast {
+			if values[c.index] != "" {
+				fields[c.field.Name] = values[c.index]
+			}
+			continue
+		}
+		value, err := Coerce(c.field, values[c.index])
+		if err != nil {
+			return nil, fmt.Errorf("field %q: %w", c.field.Name, err)
+		}
+		if value != nil {
+			fields[c.field.Name] = value
+		}
+	}
+	return &airtable.Record{Fields: fields}, nil
+}
+
+// writeBatch writes the batch, if Airtable rejects it the rows are written
+// one by one and the rejected ones are passed to reject.
+func (im *Importer) writeBatch(ctx context.Context, batch []row, result *Result, reject func([]string, error) error) error {
+	err := im.write(ctx, batch, result)
+	if err == nil {
+		return nil
+	}
+	if !rejected(err) {
+		return fmt.Errorf("cannot write rows %d-%d: %w", batch[0].line, batch[len(batch)-1].line, err)
+	}
+	for _, r := range batch {
+		if len(batch) > 1 {
+			err = im.write(ctx, []row{r}, result)
+		}
+		if err == nil {
+			continue
+		}
+		if !rejected(err) {
+			return fmt.Errorf("cannot write row %d: %w", r.line, err)
+		}
+		if err := reject(r.values, fmt.Errorf("row %d: %w", r.line, err)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rejected reports whether Airtable rejected the write for invalid values,
+// nothing is written then.
+func rejected(err error) bool {
+	var httpErr *airtable.HTTPClientError
+	return errors.As(err, &httpErr) &&
+		(httpErr.StatusCode == http.StatusBadRequest || httpErr.StatusCode == http.StatusUnprocessableEntity)
+}
+
+// write sends the batch with AddRecords or upsert.
+func (im *Importer) write(ctx context.Context, batch []row, result *Result) error {
+	records := &airtable.Records{Typecast: im.opts.Typecast}
+	for _, r := range batch {
+		records.Records = append(records.Records, r.record)
+	}
+
+	if len(im.opts.MergeOn) == 0 {
+		response, err := im.table.AddRecordsContext(ctx, records)
+		if err != nil {
+			return err
+		}
+		result.Created += len(response.Records)
+		return nil
+	}
+
+	records.PerformUpsert = &airtable.PerformUpsert{FieldsToMergeOn: im.opts.MergeOn}
+	response, err := im.table.UpdateRecordsPartialContext(ctx, records)
+	if err != nil {
+		return err
+	}
+	result.Created += len(response.CreatedRecords)
+	result.Updated += len(response.UpdatedRecords)
+	return nil
+}
@@ -0,0 +1,261 @@
+// Copyright © 2020 Mike Berezin
+//
+// Use of this source code is governed by an MIT license.
+// Details in the LICENSE file.
+
+package importer
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mehanizm/airtable"
+	"github.com/mehanizm/airtable/mock"
+	"github.com/mehanizm/airtable/xlsx"
+	"go.uber.org/mock/gomock"
+)
+
+func testSchema(t *testing.T) *airtable.TableSchema {
+	b, err := os.ReadFile("testdata/schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema := new(airtable.TableSchema)
+	if err := json.Unmarshal(b, schema); err != nil {
+		t.Fatal(err)
+	}
+	return schema
+}
+
+func testCSV(t *testing.T) *os.File {
+	f, err := os.Open("testdata/apartments.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { f.Close() })
+	return f
+}
+
+// echoRecords returns sent records as created.
+func echoRecords(_ context.Context, records *airtable.Records) (*airtable.Records, error) {
+	return &airtable.Records{Records: records.Records}, nil
+}
+
+func TestImporter_ImportCSV(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	table := mock.NewMockTableAPI(ctrl)
+
+	var sent []*airtable.Record
+	table.EXPECT().AddRecordsContext(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, records *airtable.Records) (*airtable.Records, error) {
+			sent = append(sent, records.Records...)
+			return echoRecords(ctx, records)
+		})
+
+	opts := Options{Mapping: map[string]string{
+		"Name": "Name", "Rooms": "Rooms", "Price": "Price", "Furnished": "Furnished",
+		"Tags": "Tags", "District": "District", "Available": "Available",
+	}}
+	rejects := new(bytes.Buffer)
+	result, err := New(table, testSchema(t), opts).ImportCSV(context.Background(), testCSV(t), rejects)
+	if err != nil {
+		t.Fatalf("must be no error, but was: %v", err)
+	}
+	if *result != (Result{Created: 2, Rejected: 1}) {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	expected := []map[string]any{
+		{
+			"Name": "Apartment 1", "Rooms": float64(2), "Price": 1200.5, "Furnished": true,
+			"Tags": []string{"new", "quiet"}, "District": []string{"recDistrict1"}, "Available": "2024-05-01",
+		},
+		{
+			"Name": "Apartment 3", "Rooms": float64(3), "Price": float64(1500), "Furnished": true,
+			"Tags": []string{"old"}, "District": []string{"recDistrict1"}, "Available": "2024-05-03",
+		},
+	}
+	if len(sent) != len(expected) {
+		t.Fatalf("expected %d records, but was: %v", len(expected), sent)
+	}
+	for i := range expected {
+		if !reflect.DeepEqual(sent[i].Fields, expected[i]) {
+			t.Errorf("expected: %#v\nbut got: %#v", expected[i], sent[i].Fields)
+		}
+	}
+
+	rejected, err := csv.NewReader(rejects).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rejected) != 2 || rejected[0][8] != "error" || rejected[1][0] != "Apartment 2" ||
+		!strings.Contains(rejected[1][8], `row 3: field "Rooms"`) {
+		t.Errorf("unexpected rejects: %q", rejected)
+	}
+}
+
+func TestImporter_Batches(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	table := mock.NewMockTableAPI(ctrl)
+
+	input := "Name\n"
+	for i := 0; i < 25; i++ {
+		input += fmt.Sprintf("Apartment %d\n", i)
+	}
+
+	var sizes []int
+	table.EXPECT().UpdateRecordsPartialContext(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, records *airtable.Records) (*airtable.Records, error) {
+			if !reflect.DeepEqual(records.PerformUpsert.FieldsToMergeOn, []string{"Name"}) || !records.Typecast {
+				t.Errorf("unexpected batch: %+v", records)
+			}
+			sizes = append(sizes, len(records.Records))
+			for _, record := range records.Records {
+				if record.Fields["Name"] == "Apartment 13" {
+					return nil, &airtable.HTTPClientError{StatusCode: http.StatusUnprocessableEntity, Err: errors.New("invalid value")}
+				}
+			}
+			if len(sizes) == 1 {
+				return &airtable.Records{CreatedRecords: []string{"rec1"}, UpdatedRecords: make([]string, 9)}, nil
+			}
+			return &airtable.Records{CreatedRecords: make([]string, len(records.Records))}, nil
+		}).
+		Times(13)
+
+	rejects := new(bytes.Buffer)
+	opts := Options{Typecast: true, MergeOn: []string{"Name"}}
+	result, err := New(table, testSchema(t), opts).ImportCSV(context.Background(), strings.NewReader(input), rejects)
+	if err != nil {
+		t.Fatalf("must be no error, but was: %v", err)
+	}
+	if *result != (Result{Created: 15, Updated: 9, Rejected: 1}) {
+		t.Errorf("unexpected result: %+v", result)
+	}
+	expectedSizes := []int{10, 10, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 5}
+	if !reflect.DeepEqual(sizes, expectedSizes) {
+		t.Errorf("rejected batch should be written row by row, expected %v, but was %v", expectedSizes, sizes)
+	}
+	if rejected := rejects.String(); strings.Count(rejected, "invalid value") != 1 || !strings.Contains(rejected, `Apartment 13,"row 15: `) {
+		t.Errorf("only invalid row should be rejected, but was: %s", rejected)
+	}
+
+	table.EXPECT().AddRecordsContext(gomock.Any(), gomock.Any()).Return(nil, errors.New("write failed"))
+	_, err = New(table, testSchema(t), Options{}).ImportCSV(context.Background(), strings.NewReader(input), nil)
+	if err == nil || !strings.Contains(err.Error(), "cannot write rows 2-11: write failed") {
+		t.Errorf("should be batch error, but was: %v", err)
+	}
+}
+
+func TestImporter_LineNumbers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	table := mock.NewMockTableAPI(ctrl)
+	table.EXPECT().AddRecordsContext(gomock.Any(), gomock.Any()).DoAndReturn(echoRecords)
+
+	input := "Name,Rooms\n\"Apartment\n1\",1\nApartment 2,two\n"
+	rejects := new(bytes.Buffer)
+	_, err := New(table, testSchema(t), Options{}).ImportCSV(context.Background(), strings.NewReader(input), rejects)
+	if err != nil {
+		t.Fatalf("must be no error, but was: %v", err)
+	}
+	if !strings.Contains(rejects.String(), `row 4: field ""Rooms""`) {
+		t.Errorf("line should be counted with multiline values, but was: %s", rejects.String())
+	}
+}
+
+func TestImporter_Columns(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	table := mock.NewMockTableAPI(ctrl)
+
+	_, err := New(table, testSchema(t), Options{}).ImportCSV(context.Background(), testCSV(t), nil)
+	if err == nil || !strings.Contains(err.Error(), `field "Notes" not found`) {
+		t.Errorf("should be unknown field error, but was: %v", err)
+	}
+	_, err = New(table, testSchema(t), Options{}).ImportCSV(context.Background(), strings.NewReader("Formula\n1\n"), nil)
+	if !errors.Is(err, ErrComputedField) {
+		t.Errorf("should be ErrComputedField, but was: %v", err)
+	}
+	_, err = New(table, testSchema(t), Options{Mapping: map[string]string{"Other": "Name"}}).
+		ImportCSV(context.Background(), strings.NewReader("Name\n1\n"), nil)
+	if err == nil {
+		t.Errorf("there should be an err for no mapped columns, but was nil")
+	}
+	result, err := New(table, nil, Options{}).ImportCSV(context.Background(), strings.NewReader(""), nil)
+	if err != nil || *result != (Result{}) {
+		t.Errorf("empty input should import nothing, but was: %v, %v", result, err)
+	}
+}
+
+func TestImporter_ImportXLSX(t *testing.T) {
+	var input bytes.Buffer
+	workbook := xlsx.NewWriter(&input)
+	if _, err := workbook.NewSheet("Notes"); err != nil {
+		t.Fatal(err)
+	}
+	sheet, err := workbook.NewSheet("Apartments")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows := [][]xlsx.Cell{
+		{xlsx.String("Name"), xlsx.String("Rooms"), xlsx.String("Furnished"), xlsx.String("Available")},
+		{xlsx.String("Apartment 1"), xlsx.Number(2), xlsx.Bool(true), xlsx.Date(time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC))},
+		{xlsx.String("Apartment 2"), xlsx.String("two"), xlsx.Bool(false)},
+	}
+	for _, row := range rows {
+		if err := sheet.WriteCells(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := workbook.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	ctrl := gomock.NewController(t)
+	table := mock.NewMockTableAPI(ctrl)
+	var sent []*airtable.Record
+	table.EXPECT().AddRecordsContext(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, records *airtable.Records) (*airtable.Records, error) {
+			sent = append(sent, records.Records...)
+			return echoRecords(ctx, records)
+		})
+
+	rejects := new(bytes.Buffer)
+	result, err := New(table, testSchema(t), Options{}).
+		ImportXLSX(context.Background(), bytes.NewReader(input.Bytes()), int64(input.Len()), "", rejects)
+	if err != nil {
+		t.Fatalf("must be no error, but was: %v", err)
+	}
+	if *result != (Result{Created: 1, Rejected: 1}) {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	expected := map[string]any{"Name": "Apartment 1", "Rooms": float64(2), "Furnished": true, "Available": "2024-05-01"}
+	if len(sent) != 1 || !reflect.DeepEqual(sent[0].Fields, expected) {
+		t.Errorf("expected: %#v\nbut got: %v", expected, sent)
+	}
+
+	rejectsWorkbook, err := xlsx.NewReader(bytes.NewReader(rejects.Bytes()), int64(rejects.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rejectsRows, err := rejectsWorkbook.Rows("Rejects")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rejectsRows.Close()
+	if header, err := rejectsRows.Read(); err != nil || header[4] != "error" {
+		t.Errorf("unexpected rejects header: %q, %v", header, err)
+	}
+	if row, err := rejectsRows.Read(); err != nil || row[0] != "Apartment 2" || !strings.Contains(row[4], `row 3: field "Rooms"`) {
+		t.Errorf("unexpected rejected row: %q, %v", row, err)
+	}
+}
@@ -0,0 +1,51 @@
+// Copyright © 2020 Mike Berezin
+//
+// Use of this source code is governed by an MIT license.
+// Details in the LICENSE file.
+
+package importer
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/mehanizm/airtable/xlsx"
+)
+
+// rejectsSheet name of the sheet with rejected rows.
+const rejectsSheet = "Rejects"
+
+// ImportXLSX imports the sheet of the workbook with header in the first row.
+// If sheet is empty, the sheet named after the table or the first sheet is imported.
+// Rejected rows are written as a workbook with an additional error column, rejects may be nil.
+func (im *Importer) ImportXLSX(ctx context.Context, r io.ReaderAt, size int64, sheet string, rejects io.Writer) (*Result, error) {
+	workbook, err := xlsx.NewReader(r, size)
+	if err != nil {
+		return nil, err
+	}
+	if sheet == "" && im.schema != nil && slices.Contains(workbook.Sheets(), im.schema.Name) {
+		sheet = im.schema.Name
+	}
+	rows, err := workbook.Rows(sheet)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	if rejects == nil {
+		return im.Import(ctx, rows, nil)
+	}
+
+	writer := xlsx.NewWriter(rejects)
+	rejectsRows, err := writer.NewSheet(rejectsSheet)
+	if err != nil {
+		return nil, err
+	}
+	result, err := im.Import(ctx, rows, rejectsRows)
+	if closeErr := writer.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("cannot write rejects: %w", closeErr)
+	}
+	return result, err
+}
@@ -0,0 +1,84 @@
+// Copyright © 2020 Mike Berezin
+//
+// Use of this source code is governed by an MIT license.
+// Details in the LICENSE file.
+
+package airtable
+
+import (
+	"context"
+	"io"
+	"net/url"
+)
+
+//go:generate mockgen -source=interfaces.go -destination=mock/mock.go -package=mock
+
+// TableAPI describes the operations available on a table.
+// *Table satisfies it, so code depending on TableAPI
+// can be tested with the mocks from the mock subpackage.
+type TableAPI interface {
+	GetRecordsWithParams(params url.Values) (*Records, error)
+	GetRecordsWithParamsContext(ctx context.Context, params url.Values) (*Records, error)
+	GetRecord(recordID string) (*Record, error)
+	GetRecordContext(ctx context.Context, recordID string) (*Record, error)
+	GetRecordWithParams(recordID string, params url.Values) (*Record, error)
+	GetRecordWithParamsContext(ctx context.Context, recordID string, params url.Values) (*Record, error)
+	AddRecords(records *Records) (*Records, error)
+	AddRecordsContext(ctx context.Context, records *Records) (*Records, error)
+	UpdateRecords(records *Records) (*Records, error)
+	UpdateRecordsContext(ctx context.Context, records *Records) (*Records, error)
+	UpdateRecordsPartial(records *Records) (*Records, error)
+	UpdateRecordsPartialContext(ctx context.Context, records *Records) (*Records, error)
+	Upsert(records *Records, fieldsToMergeOn ...string) (*Records, error)
+	UpsertContext(ctx context.Context, records *Records, fieldsToMergeOn ...string) (*Records, error)
+	SaveAll(records []*Record) error
+	SaveAllContext(ctx context.Context, records []*Record) error
+	DeleteRecords(recordIDs []string) (*Records, error)
+	DeleteRecordsContext(ctx context.Context, recordIDs []string) (*Records, error)
+	UploadAttachment(recordID string, attachmentFieldIdOrName string, data Attachment) (*FieldAttachments, error)
+	UploadAttachmentContext(ctx context.Context, recordID string, attachmentFieldIdOrName string, data Attachment) (*FieldAttachments, error)
+	UploadAttachmentFromReader(recordID string, attachmentFieldIdOrName string, fileName string, r io.Reader) (*FieldAttachments, error)
+	UploadAttachmentFromReaderContext(ctx context.Context, recordID string, attachmentFieldIdOrName string, fileName string, r io.Reader) (*FieldAttachments, error)
+	UploadAttachmentFromFile(recordID string, attachmentFieldIdOrName string, path string) (*FieldAttachments, error)
+	UploadAttachmentFromFileContext(ctx context.Context, recordID string, attachmentFieldIdOrName string, path string) (*FieldAttachments, error)
+}
+
+// RecordAPI describes the operations available on a single record.
+// *Record satisfies it.
+type RecordAPI interface {
+	UpdateRecordPartial(changedFields map[string]any) (*Record, error)
+	UpdateRecordPartialContext(ctx context.Context, changedFields map[string]any) (*Record, error)
+	Save() error
+	SaveContext(ctx context.Context) error
+	DeleteRecord() (*Record, error)
+	DeleteRecordContext(ctx context.Context) (*Record, error)
+}
+
+// SchemaAPI describes the base schema operations.
+// *BaseConfig satisfies it.
+type SchemaAPI interface {
+	Do() (*Tables, error)
+	DoContext(ctx context.Context) (*Tables, error)
+	GetTables() (*Tables, error)
+	GetTablesContext(ctx context.Context) (*Tables, error)
+	CreateTable(table *TableSchema) (*TableSchema, error)
+	CreateTableContext(ctx context.Context, table *TableSchema) (*TableSchema, error)
+	CreateField(tableID string, field *Field) (*Field, error)
+	CreateFieldContext(ctx context.Context, tableID string, field *Field) (*Field, error)
+	UpdateField(tableID, fieldID, name, description string) (*Field, error)
+	UpdateFieldContext(ctx context.Context, tableID, fieldID, name, description string) (*Field, error)
+}
+
+// ClientAPI describes the base listing operations of the client.
+// *Client satisfies it.
+type ClientAPI interface {
+	GetBasesWithParams(params url.Values) (*Bases, error)
+	GetBasesWithParamsContext(ctx context.Context, params url.Values) (*Bases, error)
+}
+
+var (
+	_ TableAPI  = (*Table)(nil)
+	_ RecordAPI = (*Record)(nil)
+	_ SchemaAPI = (*BaseConfig)(nil)
+	_ ClientAPI = (*Client)(nil)
+)
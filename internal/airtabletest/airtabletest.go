@@ -0,0 +1,58 @@
+// Copyright © 2020 Mike Berezin
+//
+// Use of this source code is governed by an MIT license.
+// Details in the LICENSE file.
+
+// Package airtabletest helpers for tests of the packages
+// working with Airtable through a fake server.
+package airtabletest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/mehanizm/airtable"
+)
+
+// NewClient starts test server with handler, closed on the test cleanup.
+// Returns client sending API requests and attachment uploads
+// to the server without rate limit, and URL of the server.
+func NewClient(t testing.TB, handler http.Handler) (*airtable.Client, string) {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	client := airtable.NewClient("apiKey")
+	client.SetRateLimit(1000)
+	if err := client.SetBaseURL(server.URL); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.SetUploadAttachmentBaseURL(server.URL); err != nil {
+		t.Fatal(err)
+	}
+	return client, server.URL
+}
+
+// Reply writes v as JSON response.
+func Reply(t testing.TB, w http.ResponseWriter, v any) {
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		t.Error(err)
+	}
+}
+
+// Page list records response with the page of size records
+// starting from the offset of the query, with the next offset if there are more.
+func Page(query url.Values, records []*airtable.Record, size int) map[string]any {
+	offset, _ := strconv.Atoi(query.Get("offset"))
+	offset = min(offset, len(records))
+	end := min(offset+size, len(records))
+	response := map[string]any{"records": records[offset:end]}
+	if end < len(records) {
+		response["offset"] = strconv.Itoa(end)
+	}
+	return response
+}
@@ -0,0 +1,63 @@
+// Copyright © 2020 Mike Berezin
+//
+// Use of this source code is governed by an MIT license.
+// Details in the LICENSE file.
+
+package airtable
+
+import (
+	"context"
+	"iter"
+	"sync"
+)
+
+// LoadResult all records of one request of LoadAll.
+type LoadResult struct {
+	// Index of the request in LoadAll arguments.
+	Index   int
+	Request *GetRecordsConfig
+	Records []*Record
+	// Err of the request, Records has the records read before it.
+	Err error
+}
+
+// LoadAll reads all pages of the requests in parallel and yields
+// the result of every request as soon as it completes.
+// Requests of the same client share its rate limiter, every request
+// waits for it before each page, so the pages of the requests are interleaved
+// and a large table does not hold back the small ones.
+// An error of a request does not stop the others.
+// Stopping the iteration or canceling ctx cancels the requests in progress,
+// LoadAll returns after they are finished.
+func LoadAll(ctx context.Context, requests ...*GetRecordsConfig) iter.Seq[*LoadResult] {
+	return func(yield func(*LoadResult) bool) {
+		var wg sync.WaitGroup
+		defer wg.Wait()
+		ctx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		// buffered, so requests finish without waiting for the consumer
+		results := make(chan *LoadResult, len(requests))
+		for i, grc := range requests {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				result := &LoadResult{Index: i, Request: grc}
+				for record, err := range grc.Iterate(ctx) {
+					if err != nil {
+						result.Err = err
+						break
+					}
+					result.Records = append(result.Records, record)
+				}
+				results <- result
+			}()
+		}
+
+		for range requests {
+			if !yield(<-results) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright © 2020 Mike Berezin
+//
+// Use of this source code is governed by an MIT license.
+// Details in the LICENSE file.
+
+package airtable
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// mockTablesServer serves pages of 1 record of tables named by the number
+// of records, "slow" table blocks until the request is canceled and other tables are not found.
+func mockTablesServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		table := r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]
+		if table == "slow" {
+			<-r.Context().Done()
+			return
+		}
+		count, err := strconv.Atoi(table)
+		if err != nil {
+			http.Error(rw, "Not found", http.StatusNotFound)
+			return
+		}
+		page, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		body := fmt.Sprintf(`{"records": [{"id": "rec%s_%d"}]`, table, page)
+		if page+1 < count {
+			body += fmt.Sprintf(`, "offset": "%d"`, page+1)
+		}
+		_, _ = rw.Write([]byte(body + "}"))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestLoadAll(t *testing.T) {
+	client := testClient()
+	client.baseURL = mockTablesServer(t).URL
+
+	requests := []*GetRecordsConfig{
+		client.GetTable("dbName", "3").GetRecords(),
+		client.GetTable("dbName", "unknown").GetRecords(),
+		client.GetTable("dbName", "1").GetRecords(),
+	}
+	counts := map[int]int{}
+	var httpErr *HTTPClientError
+	for result := range LoadAll(context.Background(), requests...) {
+		if result.Request != requests[result.Index] {
+			t.Errorf("result %d should have its request", result.Index)
+		}
+		counts[result.Index] = len(result.Records)
+		if result.Index == 1 && (!errors.As(result.Err, &httpErr) || httpErr.StatusCode != http.StatusNotFound) {
+			t.Errorf("should be not found error, but was: %v", result.Err)
+		}
+		if result.Index != 1 && result.Err != nil {
+			t.Errorf("must be no error, but was: %v", result.Err)
+		}
+	}
+	if len(counts) != 3 || counts[0] != 3 || counts[1] != 0 || counts[2] != 1 {
+		t.Errorf("unexpected records counts: %v", counts)
+	}
+}
+
+func TestLoadAll_Stop(t *testing.T) {
+	client := testClient()
+	client.baseURL = mockTablesServer(t).URL
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for result := range LoadAll(context.Background(),
+			client.GetTable("dbName", "slow").GetRecords(),
+			client.GetTable("dbName", "2").GetRecords(),
+		) {
+			if result.Index != 1 || result.Err != nil {
+				t.Errorf("fast table should be loaded first, but was: %+v", result)
+			}
+			break
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("slow request should be canceled when iteration is stopped")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	for result := range LoadAll(ctx, client.GetTable("dbName", "slow").GetRecords()) {
+		if !errors.Is(result.Err, context.DeadlineExceeded) {
+			t.Errorf("should be deadline exceeded, but was: %v", result.Err)
+		}
+	}
+}
@@ -0,0 +1,752 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: interfaces.go
+//
+// Generated by this command:
+//
+//	mockgen -source=interfaces.go -destination=mock/mock.go -package=mock
+//
+
+// Package mock is a generated GoMock package.
+package mock
+
+import (
+	context "context"
+	io "io"
+	url "net/url"
+	reflect "reflect"
+
+	airtable "github.com/mehanizm/airtable"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockTableAPI is a mock of TableAPI interface.
+type MockTableAPI struct {
+	ctrl     *gomock.Controller
+	recorder *MockTableAPIMockRecorder
+	isgomock struct{}
+}
+
+// MockTableAPIMockRecorder is the mock recorder for MockTableAPI.
+type MockTableAPIMockRecorder struct {
+	mock *MockTableAPI
+}
+
+// NewMockTableAPI creates a new mock instance.
+func NewMockTableAPI(ctrl *gomock.Controller) *MockTableAPI {
+	mock := &MockTableAPI{ctrl: ctrl}
+	mock.recorder = &MockTableAPIMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTableAPI) EXPECT() *MockTableAPIMockRecorder {
+	return m.recorder
+}
+
+// AddRecords mocks base method.
+func (m *MockTableAPI) AddRecords(records *airtable.Records) (*airtable.Records, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddRecords", records)
+	ret0, _ := ret[0].(*airtable.Records)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddRecords indicates an expected call of AddRecords.
+func (mr *MockTableAPIMockRecorder) AddRecords(records any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddRecords", reflect.TypeOf((*MockTableAPI)(nil).AddRecords), records)
+}
+
+// AddRecordsContext mocks base method.
+func (m *MockTableAPI) AddRecordsContext(ctx context.Context, records *airtable.Records) (*airtable.Records, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddRecordsContext", ctx, records)
+	ret0, _ := ret[0].(*airtable.Records)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddRecordsContext indicates an expected call of AddRecordsContext.
+func (mr *MockTableAPIMockRecorder) AddRecordsContext(ctx, records any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddRecordsContext", reflect.TypeOf((*MockTableAPI)(nil).AddRecordsContext), ctx, records)
+}
+
+// DeleteRecords mocks base method.
+func (m *MockTableAPI) DeleteRecords(recordIDs []string) (*airtable.Records, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteRecords", recordIDs)
+	ret0, _ := ret[0].(*airtable.Records)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteRecords indicates an expected call of DeleteRecords.
+func (mr *MockTableAPIMockRecorder) DeleteRecords(recordIDs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRecords", reflect.TypeOf((*MockTableAPI)(nil).DeleteRecords), recordIDs)
+}
+
+// DeleteRecordsContext mocks base method.
+func (m *MockTableAPI) DeleteRecordsContext(ctx context.Context, recordIDs []string) (*airtable.Records, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteRecordsContext", ctx, recordIDs)
+	ret0, _ := ret[0].(*airtable.Records)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteRecordsContext indicates an expected call of DeleteRecordsContext.
+func (mr *MockTableAPIMockRecorder) DeleteRecordsContext(ctx, recordIDs any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRecordsContext", reflect.TypeOf((*MockTableAPI)(nil).DeleteRecordsContext), ctx, recordIDs)
+}
+
+// GetRecord mocks base method.
+func (m *MockTableAPI) GetRecord(recordID string) (*airtable.Record, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRecord", recordID)
+	ret0, _ := ret[0].(*airtable.Record)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRecord indicates an expected call of GetRecord.
+func (mr *MockTableAPIMockRecorder) GetRecord(recordID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRecord", reflect.TypeOf((*MockTableAPI)(nil).GetRecord), recordID)
+}
+
+// GetRecordContext mocks base method.
+func (m *MockTableAPI) GetRecordContext(ctx context.Context, recordID string) (*airtable.Record, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRecordContext", ctx, recordID)
+	ret0, _ := ret[0].(*airtable.Record)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRecordContext indicates an expected call of GetRecordContext.
+func (mr *MockTableAPIMockRecorder) GetRecordContext(ctx, recordID any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRecordContext", reflect.TypeOf((*MockTableAPI)(nil).GetRecordContext), ctx, recordID)
+}
+
+// GetRecordWithParams mocks base method.
+func (m *MockTableAPI) GetRecordWithParams(recordID string, params url.Values) (*airtable.Record, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRecordWithParams", recordID, params)
+	ret0, _ := ret[0].(*airtable.Record)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRecordWithParams indicates an expected call of GetRecordWithParams.
+func (mr *MockTableAPIMockRecorder) GetRecordWithParams(recordID, params any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRecordWithParams", reflect.TypeOf((*MockTableAPI)(nil).GetRecordWithParams), recordID, params)
+}
+
+// GetRecordWithParamsContext mocks base method.
+func (m *MockTableAPI) GetRecordWithParamsContext(ctx context.Context, recordID string, params url.Values) (*airtable.Record, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRecordWithParamsContext", ctx, recordID, params)
+	ret0, _ := ret[0].(*airtable.Record)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRecordWithParamsContext indicates an expected call of GetRecordWithParamsContext.
+func (mr *MockTableAPIMockRecorder) GetRecordWithParamsContext(ctx, recordID, params any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRecordWithParamsContext", reflect.TypeOf((*MockTableAPI)(nil).GetRecordWithParamsContext), ctx, recordID, params)
+}
+
+// GetRecordsWithParams mocks base method.
+func (m *MockTableAPI) GetRecordsWithParams(params url.Values) (*airtable.Records, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRecordsWithParams", params)
+	ret0, _ := ret[0].(*airtable.Records)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRecordsWithParams indicates an expected call of GetRecordsWithParams.
+func (mr *MockTableAPIMockRecorder) GetRecordsWithParams(params any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRecordsWithParams", reflect.TypeOf((*MockTableAPI)(nil).GetRecordsWithParams), params)
+}
+
+// GetRecordsWithParamsContext mocks base method.
+func (m *MockTableAPI) GetRecordsWithParamsContext(ctx context.Context, params url.Values) (*airtable.Records, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetRecordsWithParamsContext", ctx, params)
+	ret0, _ := ret[0].(*airtable.Records)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetRecordsWithParamsContext indicates an expected call of GetRecordsWithParamsContext.
+func (mr *MockTableAPIMockRecorder) GetRecordsWithParamsContext(ctx, params any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRecordsWithParamsContext", reflect.TypeOf((*MockTableAPI)(nil).GetRecordsWithParamsContext), ctx, params)
+}
+
+// SaveAll mocks base method.
+func (m *MockTableAPI) SaveAll(records []*airtable.Record) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveAll", records)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveAll indicates an expected call of SaveAll.
+func (mr *MockTableAPIMockRecorder) SaveAll(records any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveAll", reflect.TypeOf((*MockTableAPI)(nil).SaveAll), records)
+}
+
+// SaveAllContext mocks base method.
+func (m *MockTableAPI) SaveAllContext(ctx context.Context, records []*airtable.Record) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveAllContext", ctx, records)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveAllContext indicates an expected call of SaveAllContext.
+func (mr *MockTableAPIMockRecorder) SaveAllContext(ctx, records any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveAllContext", reflect.TypeOf((*MockTableAPI)(nil).SaveAllContext), ctx, records)
+}
+
+// UpdateRecords mocks base method.
+func (m *MockTableAPI) UpdateRecords(records *airtable.Records) (*airtable.Records, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRecords", records)
+	ret0, _ := ret[0].(*airtable.Records)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateRecords indicates an expected call of UpdateRecords.
+func (mr *MockTableAPIMockRecorder) UpdateRecords(records any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRecords", reflect.TypeOf((*MockTableAPI)(nil).UpdateRecords), records)
+}
+
+// UpdateRecordsContext mocks base method.
+func (m *MockTableAPI) UpdateRecordsContext(ctx context.Context, records *airtable.Records) (*airtable.Records, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRecordsContext", ctx, records)
+	ret0, _ := ret[0].(*airtable.Records)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateRecordsContext indicates an expected call of UpdateRecordsContext.
+func (mr *MockTableAPIMockRecorder) UpdateRecordsContext(ctx, records any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRecordsContext", reflect.TypeOf((*MockTableAPI)(nil).UpdateRecordsContext), ctx, records)
+}
+
+// UpdateRecordsPartial mocks base method.
+func (m *MockTableAPI) UpdateRecordsPartial(records *airtable.Records) (*airtable.Records, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRecordsPartial", records)
+	ret0, _ := ret[0].(*airtable.Records)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateRecordsPartial indicates an expected call of UpdateRecordsPartial.
+func (mr *MockTableAPIMockRecorder) UpdateRecordsPartial(records any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRecordsPartial", reflect.TypeOf((*MockTableAPI)(nil).UpdateRecordsPartial), records)
+}
+
+// UpdateRecordsPartialContext mocks base method.
+func (m *MockTableAPI) UpdateRecordsPartialContext(ctx context.Context, records *airtable.Records) (*airtable.Records, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRecordsPartialContext", ctx, records)
+	ret0, _ := ret[0].(*airtable.Records)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateRecordsPartialContext indicates an expected call of UpdateRecordsPartialContext.
+func (mr *MockTableAPIMockRecorder) UpdateRecordsPartialContext(ctx, records any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRecordsPartialContext", reflect.TypeOf((*MockTableAPI)(nil).UpdateRecordsPartialContext), ctx, records)
+}
+
+// UploadAttachment mocks base method.
+func (m *MockTableAPI) UploadAttachment(recordID, attachmentFieldIdOrName string, data airtable.Attachment) (*airtable.FieldAttachments, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UploadAttachment", recordID, attachmentFieldIdOrName, data)
+	ret0, _ := ret[0].(*airtable.FieldAttachments)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UploadAttachment indicates an expected call of UploadAttachment.
+func (mr *MockTableAPIMockRecorder) UploadAttachment(recordID, attachmentFieldIdOrName, data any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UploadAttachment", reflect.TypeOf((*MockTableAPI)(nil).UploadAttachment), recordID, attachmentFieldIdOrName, data)
+}
+
+// UploadAttachmentContext mocks base method.
+func (m *MockTableAPI) UploadAttachmentContext(ctx context.Context, recordID, attachmentFieldIdOrName string, data airtable.Attachment) (*airtable.FieldAttachments, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UploadAttachmentContext", ctx, recordID, attachmentFieldIdOrName, data)
+	ret0, _ := ret[0].(*airtable.FieldAttachments)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UploadAttachmentContext indicates an expected call of UploadAttachmentContext.
+func (mr *MockTableAPIMockRecorder) UploadAttachmentContext(ctx, recordID, attachmentFieldIdOrName, data any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UploadAttachmentContext", reflect.TypeOf((*MockTableAPI)(nil).UploadAttachmentContext), ctx, recordID, attachmentFieldIdOrName, data)
+}
+
+// UploadAttachmentFromFile mocks base method.
+func (m *MockTableAPI) UploadAttachmentFromFile(recordID, attachmentFieldIdOrName, path string) (*airtable.FieldAttachments, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UploadAttachmentFromFile", recordID, attachmentFieldIdOrName, path)
+	ret0, _ := ret[0].(*airtable.FieldAttachments)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UploadAttachmentFromFile indicates an expected call of UploadAttachmentFromFile.
+func (mr *MockTableAPIMockRecorder) UploadAttachmentFromFile(recordID, attachmentFieldIdOrName, path any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UploadAttachmentFromFile", reflect.TypeOf((*MockTableAPI)(nil).UploadAttachmentFromFile), recordID, attachmentFieldIdOrName, path)
+}
+
+// UploadAttachmentFromFileContext mocks base method.
+func (m *MockTableAPI) UploadAttachmentFromFileContext(ctx context.Context, recordID, attachmentFieldIdOrName, path string) (*airtable.FieldAttachments, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UploadAttachmentFromFileContext", ctx, recordID, attachmentFieldIdOrName, path)
+	ret0, _ := ret[0].(*airtable.FieldAttachments)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UploadAttachmentFromFileContext indicates an expected call of UploadAttachmentFromFileContext.
+func (mr *MockTableAPIMockRecorder) UploadAttachmentFromFileContext(ctx, recordID, attachmentFieldIdOrName, path any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UploadAttachmentFromFileContext", reflect.TypeOf((*MockTableAPI)(nil).UploadAttachmentFromFileContext), ctx, recordID, attachmentFieldIdOrName, path)
+}
+
+// UploadAttachmentFromReader mocks base method.
+func (m *MockTableAPI) UploadAttachmentFromReader(recordID, attachmentFieldIdOrName, fileName string, r io.Reader) (*airtable.FieldAttachments, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UploadAttachmentFromReader", recordID, attachmentFieldIdOrName, fileName, r)
+	ret0, _ := ret[0].(*airtable.FieldAttachments)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UploadAttachmentFromReader indicates an expected call of UploadAttachmentFromReader.
+func (mr *MockTableAPIMockRecorder) UploadAttachmentFromReader(recordID, attachmentFieldIdOrName, fileName, r any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UploadAttachmentFromReader", reflect.TypeOf((*MockTableAPI)(nil).UploadAttachmentFromReader), recordID, attachmentFieldIdOrName, fileName, r)
+}
+
+// UploadAttachmentFromReaderContext mocks base method.
+func (m *MockTableAPI) UploadAttachmentFromReaderContext(ctx context.Context, recordID, attachmentFieldIdOrName, fileName string, r io.Reader) (*airtable.FieldAttachments, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UploadAttachmentFromReaderContext", ctx, recordID, attachmentFieldIdOrName, fileName, r)
+	ret0, _ := ret[0].(*airtable.FieldAttachments)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UploadAttachmentFromReaderContext indicates an expected call of UploadAttachmentFromReaderContext.
+func (mr *MockTableAPIMockRecorder) UploadAttachmentFromReaderContext(ctx, recordID, attachmentFieldIdOrName, fileName, r any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UploadAttachmentFromReaderContext", reflect.TypeOf((*MockTableAPI)(nil).UploadAttachmentFromReaderContext), ctx, recordID, attachmentFieldIdOrName, fileName, r)
+}
+
+// Upsert mocks base method.
+func (m *MockTableAPI) Upsert(records *airtable.Records, fieldsToMergeOn ...string) (*airtable.Records, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{records}
+	for _, a := range fieldsToMergeOn {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Upsert", varargs...)
+	ret0, _ := ret[0].(*airtable.Records)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Upsert indicates an expected call of Upsert.
+func (mr *MockTableAPIMockRecorder) Upsert(records any, fieldsToMergeOn ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{records}, fieldsToMergeOn...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Upsert", reflect.TypeOf((*MockTableAPI)(nil).Upsert), varargs...)
+}
+
+// UpsertContext mocks base method.
+func (m *MockTableAPI) UpsertContext(ctx context.Context, records *airtable.Records, fieldsToMergeOn ...string) (*airtable.Records, error) {
+	m.ctrl.T.Helper()
+	varargs := []any{ctx, records}
+	for _, a := range fieldsToMergeOn {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "UpsertContext", varargs...)
+	ret0, _ := ret[0].(*airtable.Records)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpsertContext indicates an expected call of UpsertContext.
+func (mr *MockTableAPIMockRecorder) UpsertContext(ctx, records any, fieldsToMergeOn ...any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]any{ctx, records}, fieldsToMergeOn...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpsertContext", reflect.TypeOf((*MockTableAPI)(nil).UpsertContext), varargs...)
+}
+
+// MockRecordAPI is a mock of RecordAPI interface.
+type MockRecordAPI struct {
+	ctrl     *gomock.Controller
+	recorder *MockRecordAPIMockRecorder
+	isgomock struct{}
+}
+
+// MockRecordAPIMockRecorder is the mock recorder for MockRecordAPI.
+type MockRecordAPIMockRecorder struct {
+	mock *MockRecordAPI
+}
+
+// NewMockRecordAPI creates a new mock instance.
+func NewMockRecordAPI(ctrl *gomock.Controller) *MockRecordAPI {
+	mock := &MockRecordAPI{ctrl: ctrl}
+	mock.recorder = &MockRecordAPIMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRecordAPI) EXPECT() *MockRecordAPIMockRecorder {
+	return m.recorder
+}
+
+// DeleteRecord mocks base method.
+func (m *MockRecordAPI) DeleteRecord() (*airtable.Record, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteRecord")
+	ret0, _ := ret[0].(*airtable.Record)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteRecord indicates an expected call of DeleteRecord.
+func (mr *MockRecordAPIMockRecorder) DeleteRecord() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRecord", reflect.TypeOf((*MockRecordAPI)(nil).DeleteRecord))
+}
+
+// DeleteRecordContext mocks base method.
+func (m *MockRecordAPI) DeleteRecordContext(ctx context.Context) (*airtable.Record, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteRecordContext", ctx)
+	ret0, _ := ret[0].(*airtable.Record)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteRecordContext indicates an expected call of DeleteRecordContext.
+func (mr *MockRecordAPIMockRecorder) DeleteRecordContext(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteRecordContext", reflect.TypeOf((*MockRecordAPI)(nil).DeleteRecordContext), ctx)
+}
+
+// Save mocks base method.
+func (m *MockRecordAPI) Save() error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Save")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Save indicates an expected call of Save.
+func (mr *MockRecordAPIMockRecorder) Save() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Save", reflect.TypeOf((*MockRecordAPI)(nil).Save))
+}
+
+// SaveContext mocks base method.
+func (m *MockRecordAPI) SaveContext(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SaveContext", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveContext indicates an expected call of SaveContext.
+func (mr *MockRecordAPIMockRecorder) SaveContext(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveContext", reflect.TypeOf((*MockRecordAPI)(nil).SaveContext), ctx)
+}
+
+// UpdateRecordPartial mocks base method.
+func (m *MockRecordAPI) UpdateRecordPartial(changedFields map[string]any) (*airtable.Record, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRecordPartial", changedFields)
+	ret0, _ := ret[0].(*airtable.Record)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateRecordPartial indicates an expected call of UpdateRecordPartial.
+func (mr *MockRecordAPIMockRecorder) UpdateRecordPartial(changedFields any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRecordPartial", reflect.TypeOf((*MockRecordAPI)(nil).UpdateRecordPartial), changedFields)
+}
+
+// UpdateRecordPartialContext mocks base method.
+func (m *MockRecordAPI) UpdateRecordPartialContext(ctx context.Context, changedFields map[string]any) (*airtable.Record, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateRecordPartialContext", ctx, changedFields)
+	ret0, _ := ret[0].(*airtable.Record)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateRecordPartialContext indicates an expected call of UpdateRecordPartialContext.
+func (mr *MockRecordAPIMockRecorder) UpdateRecordPartialContext(ctx, changedFields any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateRecordPartialContext", reflect.TypeOf((*MockRecordAPI)(nil).UpdateRecordPartialContext), ctx, changedFields)
+}
+
+// MockSchemaAPI is a mock of SchemaAPI interface.
+type MockSchemaAPI struct {
+	ctrl     *gomock.Controller
+	recorder *MockSchemaAPIMockRecorder
+	isgomock struct{}
+}
+
+// MockSchemaAPIMockRecorder is the mock recorder for MockSchemaAPI.
+type MockSchemaAPIMockRecorder struct {
+	mock *MockSchemaAPI
+}
+
+// NewMockSchemaAPI creates a new mock instance.
+func NewMockSchemaAPI(ctrl *gomock.Controller) *MockSchemaAPI {
+	mock := &MockSchemaAPI{ctrl: ctrl}
+	mock.recorder = &MockSchemaAPIMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSchemaAPI) EXPECT() *MockSchemaAPIMockRecorder {
+	return m.recorder
+}
+
+// CreateField mocks base method.
+func (m *MockSchemaAPI) CreateField(tableID string, field *airtable.Field) (*airtable.Field, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateField", tableID, field)
+	ret0, _ := ret[0].(*airtable.Field)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateField indicates an expected call of CreateField.
+func (mr *MockSchemaAPIMockRecorder) CreateField(tableID, field any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateField", reflect.TypeOf((*MockSchemaAPI)(nil).CreateField), tableID, field)
+}
+
+// CreateFieldContext mocks base method.
+func (m *MockSchemaAPI) CreateFieldContext(ctx context.Context, tableID string, field *airtable.Field) (*airtable.Field, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateFieldContext", ctx, tableID, field)
+	ret0, _ := ret[0].(*airtable.Field)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateFieldContext indicates an expected call of CreateFieldContext.
+func (mr *MockSchemaAPIMockRecorder) CreateFieldContext(ctx, tableID, field any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateFieldContext", reflect.TypeOf((*MockSchemaAPI)(nil).CreateFieldContext), ctx, tableID, field)
+}
+
+// CreateTable mocks base method.
+func (m *MockSchemaAPI) CreateTable(table *airtable.TableSchema) (*airtable.TableSchema, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTable", table)
+	ret0, _ := ret[0].(*airtable.TableSchema)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTable indicates an expected call of CreateTable.
+func (mr *MockSchemaAPIMockRecorder) CreateTable(table any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTable", reflect.TypeOf((*MockSchemaAPI)(nil).CreateTable), table)
+}
+
+// CreateTableContext mocks base method.
+func (m *MockSchemaAPI) CreateTableContext(ctx context.Context, table *airtable.TableSchema) (*airtable.TableSchema, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateTableContext", ctx, table)
+	ret0, _ := ret[0].(*airtable.TableSchema)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateTableContext indicates an expected call of CreateTableContext.
+func (mr *MockSchemaAPIMockRecorder) CreateTableContext(ctx, table any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateTableContext", reflect.TypeOf((*MockSchemaAPI)(nil).CreateTableContext), ctx, table)
+}
+
+// Do mocks base method.
+func (m *MockSchemaAPI) Do() (*airtable.Tables, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Do")
+	ret0, _ := ret[0].(*airtable.Tables)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Do indicates an expected call of Do.
+func (mr *MockSchemaAPIMockRecorder) Do() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Do", reflect.TypeOf((*MockSchemaAPI)(nil).Do))
+}
+
+// DoContext mocks base method.
+func (m *MockSchemaAPI) DoContext(ctx context.Context) (*airtable.Tables, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DoContext", ctx)
+	ret0, _ := ret[0].(*airtable.Tables)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DoContext indicates an expected call of DoContext.
+func (mr *MockSchemaAPIMockRecorder) DoContext(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DoContext", reflect.TypeOf((*MockSchemaAPI)(nil).DoContext), ctx)
+}
+
+// GetTables mocks base method.
+func (m *MockSchemaAPI) GetTables() (*airtable.Tables, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTables")
+	ret0, _ := ret[0].(*airtable.Tables)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTables indicates an expected call of GetTables.
+func (mr *MockSchemaAPIMockRecorder) GetTables() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTables", reflect.TypeOf((*MockSchemaAPI)(nil).GetTables))
+}
+
+// GetTablesContext mocks base method.
+func (m *MockSchemaAPI) GetTablesContext(ctx context.Context) (*airtable.Tables, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTablesContext", ctx)
+	ret0, _ := ret[0].(*airtable.Tables)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTablesContext indicates an expected call of GetTablesContext.
+func (mr *MockSchemaAPIMockRecorder) GetTablesContext(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTablesContext", reflect.TypeOf((*MockSchemaAPI)(nil).GetTablesContext), ctx)
+}
+
+// UpdateField mocks base method.
+func (m *MockSchemaAPI) UpdateField(tableID, fieldID, name, description string) (*airtable.Field, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateField", tableID, fieldID, name, description)
+	ret0, _ := ret[0].(*airtable.Field)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateField indicates an expected call of UpdateField.
+func (mr *MockSchemaAPIMockRecorder) UpdateField(tableID, fieldID, name, description any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateField", reflect.TypeOf((*MockSchemaAPI)(nil).UpdateField), tableID, fieldID, name, description)
+}
+
+// UpdateFieldContext mocks base method.
+func (m *MockSchemaAPI) UpdateFieldContext(ctx context.Context, tableID, fieldID, name, description string) (*airtable.Field, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateFieldContext", ctx, tableID, fieldID, name, description)
+	ret0, _ := ret[0].(*airtable.Field)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateFieldContext indicates an expected call of UpdateFieldContext.
+func (mr *MockSchemaAPIMockRecorder) UpdateFieldContext(ctx, tableID, fieldID, name, description any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateFieldContext", reflect.TypeOf((*MockSchemaAPI)(nil).UpdateFieldContext), ctx, tableID, fieldID, name, description)
+}
+
+// MockClientAPI is a mock of ClientAPI interface.
+type MockClientAPI struct {
+	ctrl     *gomock.Controller
+	recorder *MockClientAPIMockRecorder
+	isgomock struct{}
+}
+
+// MockClientAPIMockRecorder is the mock recorder for MockClientAPI.
+type MockClientAPIMockRecorder struct {
+	mock *MockClientAPI
+}
+
+// NewMockClientAPI creates a new mock instance.
+func NewMockClientAPI(ctrl *gomock.Controller) *MockClientAPI {
+	mock := &MockClientAPI{ctrl: ctrl}
+	mock.recorder = &MockClientAPIMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockClientAPI) EXPECT() *MockClientAPIMockRecorder {
+	return m.recorder
+}
+
+// GetBasesWithParams mocks base method.
+func (m *MockClientAPI) GetBasesWithParams(params url.Values) (*airtable.Bases, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBasesWithParams", params)
+	ret0, _ := ret[0].(*airtable.Bases)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBasesWithParams indicates an expected call of GetBasesWithParams.
+func (mr *MockClientAPIMockRecorder) GetBasesWithParams(params any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBasesWithParams", reflect.TypeOf((*MockClientAPI)(nil).GetBasesWithParams), params)
+}
+
+// GetBasesWithParamsContext mocks base method.
+func (m *MockClientAPI) GetBasesWithParamsContext(ctx context.Context, params url.Values) (*airtable.Bases, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBasesWithParamsContext", ctx, params)
+	ret0, _ := ret[0].(*airtable.Bases)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBasesWithParamsContext indicates an expected call of GetBasesWithParamsContext.
+func (mr *MockClientAPIMockRecorder) GetBasesWithParamsContext(ctx, params any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBasesWithParamsContext", reflect.TypeOf((*MockClientAPI)(nil).GetBasesWithParamsContext), ctx, params)
+}
@@ -0,0 +1,229 @@
+// Copyright © 2020 Mike Berezin
+//
+// Use of this source code is governed by an MIT license.
+// Details in the LICENSE file.
+
+// Package query joins records of several Airtable tables in memory.
+//
+// The first table is streamed, joined tables are loaded once into hash indexes,
+// so only the joined tables are held in memory.
+//
+//	rows := query.From(apartments.GetRecords().Iterate(ctx)).
+//		InnerJoin(districts.GetRecords().Iterate(ctx), query.OnLink(0, "District")).
+//		Rows()
+//	for row, err := range rows {
+//		...
+//	}
+package query
+
+import (
+	"fmt"
+	"iter"
+
+	"github.com/mehanizm/airtable"
+)
+
+// Source records of one table, for example GetRecordsConfig.Iterate.
+type Source = iter.Seq2[*airtable.Record, error]
+
+// Row one joined row with records in the order tables were added to the query.
+// Record of the left join without match is nil.
+type Row []*airtable.Record
+
+// Condition matches rows with records of the joined table by keys.
+// Row and record match if they have at least one common key.
+type Condition struct {
+	RowKeys    func(row Row) []string
+	RecordKeys func(record *airtable.Record) []string
+}
+
+// On custom join condition.
+func On(rowKeys func(row Row) []string, recordKeys func(record *airtable.Record) []string) Condition {
+	return Condition{RowKeys: rowKeys, RecordKeys: recordKeys}
+}
+
+// OnLink joins records linked in the link field of the row record at index.
+func OnLink(index int, field string) Condition {
+	return On(
+		func(row Row) []string { return fieldKeys(row.at(index), field) },
+		recordID,
+	)
+}
+
+// OnBacklink joins records which link field contains the row record at index.
+func OnBacklink(index int, field string) Condition {
+	return On(
+		func(row Row) []string { return recordID(row.at(index)) },
+		func(record *airtable.Record) []string { return fieldKeys(record, field) },
+	)
+}
+
+// OnFields joins records with equal values of the fields.
+// Values of list fields match by any item.
+func OnFields(index int, rowField, recordField string) Condition {
+	return On(
+		func(row Row) []string { return fieldKeys(row.at(index), rowField) },
+		func(record *airtable.Record) []string { return fieldKeys(record, recordField) },
+	)
+}
+
+type joinKind int
+
+const (
+	innerJoin joinKind = iota
+	leftJoin
+)
+
+type join struct {
+	kind      joinKind
+	source    Source
+	condition Condition
+}
+
+// Query joins records of the tables.
+type Query struct {
+	source Source
+	joins  []join
+}
+
+// From starts query from the streamed table.
+func From(source Source) *Query {
+	return &Query{source: source}
+}
+
+// InnerJoin add table which records must match the condition.
+func (q *Query) InnerJoin(source Source, condition Condition) *Query {
+	q.joins = append(q.joins, join{kind: innerJoin, source: source, condition: condition})
+	return q
+}
+
+// LeftJoin add table which records are joined if match the condition,
+// rows without match get nil record.
+func (q *Query) LeftJoin(source Source, condition Condition) *Query {
+	q.joins = append(q.joins, join{kind: leftJoin, source: source, condition: condition})
+	return q
+}
+
+// Rows loads joined tables and streams joined rows.
+// Iteration stops after the first error.
+func (q *Query) Rows() iter.Seq2[Row, error] {
+	return func(yield func(Row, error) bool) {
+		indexes := make([]map[string][]*airtable.Record, len(q.joins))
+		for i, j := range q.joins {
+			index, err := buildIndex(j.source, j.condition)
+			if err != nil {
+				yield(nil, fmt.Errorf("cannot load joined table %d: %w", i+1, err))
+				return
+			}
+			indexes[i] = index
+		}
+
+		for record, err := range q.source {
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if !q.emit(Row{record}, indexes, yield) {
+				return
+			}
+		}
+	}
+}
+
+// emit joins the row with the rest of tables and yields results.
+func (q *Query) emit(row Row, indexes []map[string][]*airtable.Record, yield func(Row, error) bool) bool {
+	joined := len(row) - 1
+	if joined == len(q.joins) {
+		return yield(row, nil)
+	}
+
+	j := q.joins[joined]
+	matches := lookup(indexes[joined], j.condition.RowKeys(row))
+	if len(matches) == 0 && j.kind == leftJoin {
+		matches = []*airtable.Record{nil}
+	}
+	for _, match := range matches {
+		next := make(Row, len(row), len(row)+1)
+		copy(next, row)
+		if !q.emit(append(next, match), indexes, yield) {
+			return false
+		}
+	}
+	return true
+}
+
+func buildIndex(source Source, condition Condition) (map[string][]*airtable.Record, error) {
+	index := map[string][]*airtable.Record{}
+	for record, err := range source {
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range unique(condition.RecordKeys(record)) {
+			index[key] = append(index[key], record)
+		}
+	}
+	return index, nil
+}
+
+// lookup returns records matching any of the keys without duplicates.
+func lookup(index map[string][]*airtable.Record, keys []string) []*airtable.Record {
+	var result []*airtable.Record
+	seen := map[*airtable.Record]struct{}{}
+	for _, key := range keys {
+		for _, record := range index[key] {
+			if _, ok := seen[record]; !ok {
+				seen[record] = struct{}{}
+				result = append(result, record)
+			}
+		}
+	}
+	return result
+}
+
+func (row Row) at(index int) *airtable.Record {
+	if index < 0 || index >= len(row) {
+		return nil
+	}
+	return row[index]
+}
+
+func recordID(record *airtable.Record) []string {
+	if record == nil {
+		return nil
+	}
+	return []string{record.ID}
+}
+
+// fieldKeys returns field value as keys, each item for lists.
+func fieldKeys(record *airtable.Record, field string) []string {
+	if record == nil {
+		return nil
+	}
+	value := record.Fields[field]
+	if value == nil {
+		return nil
+	}
+	if items, ok := value.([]any); ok {
+		keys := make([]string, 0, len(items))
+		for _, item := range items {
+			keys = append(keys, fmt.Sprint(item))
+		}
+		return keys
+	}
+	if items, err := airtable.ToStrings(value); err == nil {
+		return items
+	}
+	return []string{fmt.Sprint(value)}
+}
+
+func unique(keys []string) []string {
+	seen := make(map[string]struct{}, len(keys))
+	result := keys[:0:0]
+	for _, key := range keys {
+		if _, ok := seen[key]; !ok {
+			seen[key] = struct{}{}
+			result = append(result, key)
+		}
+	}
+	return result
+}
@@ -0,0 +1,111 @@
+// Copyright © 2020 Mike Berezin
+//
+// Use of this source code is governed by an MIT license.
+// Details in the LICENSE file.
+
+package query
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/mehanizm/airtable"
+)
+
+func testSource(records ...*airtable.Record) Source {
+	return func(yield func(*airtable.Record, error) bool) {
+		for _, record := range records {
+			if !yield(record, nil) {
+				return
+			}
+		}
+	}
+}
+
+func errorSource(err error) Source {
+	return func(yield func(*airtable.Record, error) bool) {
+		yield(nil, err)
+	}
+}
+
+var (
+	apartment1 = &airtable.Record{ID: "recA1", Fields: map[string]any{"Name": "A1", "District": []any{"recD1"}, "Owner": "Alice"}}
+	apartment2 = &airtable.Record{ID: "recA2", Fields: map[string]any{"Name": "A2", "District": []any{"recD2"}, "Owner": "Bob"}}
+	apartment3 = &airtable.Record{ID: "recA3", Fields: map[string]any{"Name": "A3"}}
+	district1  = &airtable.Record{ID: "recD1", Fields: map[string]any{"Name": "D1", "Apartments": []any{"recA1"}}}
+	owner1     = &airtable.Record{ID: "recO1", Fields: map[string]any{"Name": "Alice"}}
+	owner2     = &airtable.Record{ID: "recO2", Fields: map[string]any{"Name": "Alice"}}
+)
+
+func collect(t *testing.T, q *Query) []Row {
+	var rows []Row
+	for row, err := range q.Rows() {
+		if err != nil {
+			t.Fatalf("there should not be an err, but was: %v", err)
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+func TestQuery_InnerJoin(t *testing.T) {
+	rows := collect(t, From(testSource(apartment1, apartment2, apartment3)).
+		InnerJoin(testSource(district1), OnLink(0, "District")))
+	expected := []Row{{apartment1, district1}}
+	if !reflect.DeepEqual(rows, expected) {
+		t.Errorf("expected: %v\nbut got: %v", expected, rows)
+	}
+
+	rows = collect(t, From(testSource(apartment1, apartment2)).
+		InnerJoin(testSource(district1), OnBacklink(0, "Apartments")))
+	if !reflect.DeepEqual(rows, expected) {
+		t.Errorf("expected: %v\nbut got: %v", expected, rows)
+	}
+}
+
+func TestQuery_LeftJoin(t *testing.T) {
+	rows := collect(t, From(testSource(apartment1, apartment2, apartment3)).
+		LeftJoin(testSource(district1), OnLink(0, "District")).
+		LeftJoin(testSource(owner1, owner2), OnFields(0, "Owner", "Name")))
+	expected := []Row{
+		{apartment1, district1, owner1},
+		{apartment1, district1, owner2},
+		{apartment2, nil, nil},
+		{apartment3, nil, nil},
+	}
+	if !reflect.DeepEqual(rows, expected) {
+		t.Errorf("expected: %v\nbut got: %v", expected, rows)
+	}
+}
+
+func TestQuery_Errors(t *testing.T) {
+	testErr := errors.New("test error")
+
+	var gotErr error
+	for _, err := range From(errorSource(testErr)).Rows() {
+		gotErr = err
+	}
+	if !errors.Is(gotErr, testErr) {
+		t.Errorf("should be source error, but was: %v", gotErr)
+	}
+
+	gotErr = nil
+	for _, err := range From(testSource(apartment1)).InnerJoin(errorSource(testErr), OnLink(0, "District")).Rows() {
+		gotErr = err
+	}
+	if !errors.Is(gotErr, testErr) {
+		t.Errorf("should be joined table error, but was: %v", gotErr)
+	}
+}
+
+func TestQuery_Break(t *testing.T) {
+	count := 0
+	for range From(testSource(apartment1, apartment2, apartment3)).LeftJoin(testSource(district1), OnLink(0, "District")).Rows() {
+		count++
+		break
+	}
+	if count != 1 {
+		t.Errorf("iteration should stop on break, but was %d rows", count)
+	}
+}
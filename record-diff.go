@@ -0,0 +1,257 @@
+// Copyright © 2020 Mike Berezin
+//
+// Use of this source code is governed by an MIT license.
+// Details in the LICENSE file.
+
+package airtable
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+)
+
+// FieldChange one changed field of the record.
+type FieldChange struct {
+	Field string
+	From  any
+	To    any
+}
+
+// RecordDiff changes between two versions of the record.
+type RecordDiff struct {
+	ID      string
+	Changes []FieldChange
+}
+
+// IsEmpty reports whether there are no changes.
+func (d *RecordDiff) IsEmpty() bool {
+	return len(d.Changes) == 0
+}
+
+// Patch returns record with changed fields to send with UpdateRecordsPartial.
+// Removed fields are set to nil to be cleared.
+func (d *RecordDiff) Patch() *Record {
+	fields := make(map[string]any, len(d.Changes))
+	for _, change := range d.Changes {
+		fields[change.Field] = change.To
+	}
+	return &Record{ID: d.ID, Fields: fields}
+}
+
+// DiffRecords compares fields of two versions of the record
+// with FieldsEqual and returns changes sorted by field name.
+func DiffRecords(from, to *Record) *RecordDiff {
+	diff := &RecordDiff{ID: to.ID}
+	if diff.ID == "" {
+		diff.ID = from.ID
+	}
+	for _, name := range fieldNames(from.Fields, to.Fields) {
+		if !FieldsEqual(from.Fields[name], to.Fields[name]) {
+			diff.Changes = append(diff.Changes, FieldChange{
+				Field: name,
+				From:  from.Fields[name],
+				To:    to.Fields[name],
+			})
+		}
+	}
+	return diff
+}
+
+// PatchRecords collects patches of non empty diffs to send with UpdateRecordsPartial.
+func PatchRecords(diffs ...*RecordDiff) *Records {
+	records := new(Records)
+	for _, diff := range diffs {
+		if !diff.IsEmpty() {
+			records.Records = append(records.Records, diff.Patch())
+		}
+	}
+	return records
+}
+
+// MergeConflict field changed differently in local and remote versions.
+type MergeConflict struct {
+	Field  string
+	Base   any
+	Local  any
+	Remote any
+}
+
+// MergeResult result of three-way merge.
+type MergeResult struct {
+	// Fields merged fields, remote value is used for conflicts.
+	Fields map[string]any
+	// Patch local changes to send to Airtable, without conflicting fields.
+	Patch *Record
+	// Conflicts fields changed both locally and remotely to different values.
+	Conflicts []MergeConflict
+}
+
+// Merge performs three-way merge of local and remote versions of the record
+// changed since the common base version.
+func Merge(base, local, remote *Record) *MergeResult {
+	result := &MergeResult{
+		Fields: map[string]any{},
+		Patch:  &Record{ID: remote.ID, Fields: map[string]any{}},
+	}
+	for _, name := range fieldNames(base.Fields, local.Fields, remote.Fields) {
+		baseValue, localValue, remoteValue := base.Fields[name], local.Fields[name], remote.Fields[name]
+		localChanged := !FieldsEqual(baseValue, localValue)
+		remoteChanged := !FieldsEqual(baseValue, remoteValue)
+
+		switch {
+		case localChanged && !remoteChanged:
+			result.Patch.Fields[name] = localValue
+			if localValue != nil {
+				result.Fields[name] = localValue
+			}
+			continue
+		case localChanged && remoteChanged && !FieldsEqual(localValue, remoteValue):
+			result.Conflicts = append(result.Conflicts, MergeConflict{
+				Field:  name,
+				Base:   baseValue,
+				Local:  localValue,
+				Remote: remoteValue,
+			})
+		}
+		if remoteValue != nil {
+			result.Fields[name] = remoteValue
+		}
+	}
+	return result
+}
+
+// FieldsEqual compares field values the way Airtable stores them:
+// numbers by value, date times by instant, lists of strings (selects, linked records)
+// regardless of order, attachments and collaborators by ID,
+// and omitted field equal to empty value.
+func FieldsEqual(a, b any) bool {
+	return valuesEqual(normalizeValue(a), normalizeValue(b))
+}
+
+// normalizeValue converts value to its JSON representation,
+// the same types Airtable values are decoded to.
+func normalizeValue(v any) any {
+	switch v.(type) {
+	case nil, string, float64, bool:
+		return v
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	var normalized any
+	if err := json.Unmarshal(b, &normalized); err != nil {
+		return v
+	}
+	return normalized
+}
+
+func valuesEqual(a, b any) bool {
+	if isEmptyValue(a) && isEmptyValue(b) {
+		return true
+	}
+	switch a := a.(type) {
+	case string:
+		b, ok := b.(string)
+		if !ok {
+			return false
+		}
+		if a == b {
+			return true
+		}
+		ta, errA := parseDateTime(a)
+		tb, errB := parseDateTime(b)
+		return errA == nil && errB == nil && ta.Equal(tb)
+	case []any:
+		b, ok := b.([]any)
+		return ok && listsEqual(a, b)
+	case map[string]any:
+		b, ok := b.(map[string]any)
+		if !ok {
+			return false
+		}
+		if idA, ok := a["id"]; ok {
+			return idA == b["id"]
+		}
+		if len(a) != len(b) {
+			return false
+		}
+		for key, value := range a {
+			if !valuesEqual(value, b[key]) {
+				return false
+			}
+		}
+		return true
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func listsEqual(a, b []any) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	keysA, okA := listKeys(a)
+	keysB, okB := listKeys(b)
+	if okA && okB {
+		return reflect.DeepEqual(keysA, keysB)
+	}
+	for i := range a {
+		if !valuesEqual(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// listKeys returns sorted strings or object IDs of the list
+// if all its items are strings or objects with ID.
+func listKeys(list []any) ([]string, bool) {
+	keys := make([]string, 0, len(list))
+	for _, item := range list {
+		switch item := item.(type) {
+		case string:
+			keys = append(keys, item)
+		case map[string]any:
+			id, ok := item["id"].(string)
+			if !ok {
+				return nil, false
+			}
+			keys = append(keys, id)
+		default:
+			return nil, false
+		}
+	}
+	sort.Strings(keys)
+	return keys, true
+}
+
+func isEmptyValue(v any) bool {
+	switch v := v.(type) {
+	case nil:
+		return true
+	case string:
+		return v == ""
+	case bool:
+		return !v
+	case []any:
+		return len(v) == 0
+	}
+	return false
+}
+
+// fieldNames returns sorted union of field names.
+func fieldNames(fields ...map[string]any) []string {
+	seen := map[string]struct{}{}
+	for _, f := range fields {
+		for name := range f {
+			seen[name] = struct{}{}
+		}
+	}
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,104 @@
+// Copyright © 2020 Mike Berezin
+//
+// Use of this source code is governed by an MIT license.
+// Details in the LICENSE file.
+
+package airtable
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFieldsEqual(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b any
+		want bool
+	}{
+		{"numbers", float64(3), 3, true},
+		{"different numbers", float64(3), 3.5, false},
+		{"omitted checkbox", nil, false, true},
+		{"omitted text", nil, "", true},
+		{"zero is not empty", nil, float64(0), false},
+		{"date time formats", "2020-04-06T06:00:00.000Z", "2020-04-06T09:00:00+03:00", true},
+		{"time value", "2020-04-06T06:00:00.000Z", time.Date(2020, 4, 6, 6, 0, 0, 0, time.UTC), true},
+		{"different strings", "a", "b", false},
+		{"unordered selects", []any{"a", "b"}, []string{"b", "a"}, true},
+		{"different selects", []any{"a", "b"}, []any{"a", "a"}, false},
+		{"empty list", nil, []any{}, true},
+		{
+			"attachments by id",
+			[]any{map[string]any{"id": "att1", "url": "https://example.com/1"}},
+			[]any{map[string]any{"id": "att1", "url": "https://example.com/2"}},
+			true,
+		},
+		{
+			"attachments order",
+			[]any{map[string]any{"id": "att1"}, map[string]any{"id": "att2"}},
+			[]any{map[string]any{"id": "att2"}, map[string]any{"id": "att1"}},
+			true,
+		},
+		{"collaborator by id", map[string]any{"id": "usr1", "name": "A"}, Collaborator{ID: "usr1"}, true},
+		{"barcode", map[string]any{"text": "1", "type": "upce"}, Barcode{Text: "1", Type: "ean8"}, false},
+		{"different types", "1", float64(1), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FieldsEqual(tt.a, tt.b); got != tt.want {
+				t.Errorf("FieldsEqual(%v, %v) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffRecords(t *testing.T) {
+	from := &Record{ID: "rec1", Fields: map[string]any{
+		"Name":  "Name",
+		"Count": float64(1),
+		"Tags":  []any{"a", "b"},
+		"Notes": "notes",
+	}}
+	to := &Record{Fields: map[string]any{
+		"Name":  "Name",
+		"Count": 2,
+		"Tags":  []string{"b", "a"},
+		"Done":  true,
+	}}
+	diff := DiffRecords(from, to)
+	expected := &RecordDiff{ID: "rec1", Changes: []FieldChange{
+		{Field: "Count", From: float64(1), To: 2},
+		{Field: "Done", From: nil, To: true},
+		{Field: "Notes", From: "notes", To: nil},
+	}}
+	if !reflect.DeepEqual(diff, expected) {
+		t.Fatalf("expected: %#v\nbut got: %#v", expected, diff)
+	}
+	patch := PatchRecords(diff, DiffRecords(from, from))
+	expectedPatch := &Records{Records: []*Record{{ID: "rec1", Fields: map[string]any{"Count": 2, "Done": true, "Notes": nil}}}}
+	if !reflect.DeepEqual(patch, expectedPatch) {
+		t.Errorf("expected: %#v\nbut got: %#v", expectedPatch, patch)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	base := &Record{ID: "rec1", Fields: map[string]any{"A": "a", "B": "b", "C": "c", "D": "d", "E": "e"}}
+	local := &Record{ID: "rec1", Fields: map[string]any{"A": "a", "B": "local", "C": "c", "D": "local", "E": "same"}}
+	remote := &Record{ID: "rec1", Fields: map[string]any{"A": "a", "B": "b", "C": "remote", "D": "remote", "E": "same"}}
+
+	result := Merge(base, local, remote)
+
+	expectedFields := map[string]any{"A": "a", "B": "local", "C": "remote", "D": "remote", "E": "same"}
+	if !reflect.DeepEqual(result.Fields, expectedFields) {
+		t.Errorf("expected fields: %v, but got: %v", expectedFields, result.Fields)
+	}
+	expectedPatch := &Record{ID: "rec1", Fields: map[string]any{"B": "local"}}
+	if !reflect.DeepEqual(result.Patch, expectedPatch) {
+		t.Errorf("expected patch: %v, but got: %v", expectedPatch, result.Patch)
+	}
+	expectedConflicts := []MergeConflict{{Field: "D", Base: "d", Local: "local", Remote: "remote"}}
+	if !reflect.DeepEqual(result.Conflicts, expectedConflicts) {
+		t.Errorf("expected conflicts: %v, but got: %v", expectedConflicts, result.Conflicts)
+	}
+}
@@ -0,0 +1,203 @@
+// Copyright © 2020 Mike Berezin
+//
+// Use of this source code is governed by an MIT license.
+// Details in the LICENSE file.
+
+package airtable
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+)
+
+var (
+	// ErrNoTable returned when saving record which was not received from a table.
+	ErrNoTable = errors.New("record is not bound to a table")
+	// ErrRecordChanged returned by SaveIfUnchanged when the changed fields
+	// were modified on the server since the record was read.
+	ErrRecordChanged = errors.New("record was changed concurrently")
+)
+
+// Set sets field value and marks it as changed to be sent by Save.
+func (r *Record) Set(name string, value any) {
+	if r.Fields == nil {
+		r.Fields = map[string]any{}
+	}
+	if r.original == nil {
+		r.original = map[string]any{}
+	}
+	if _, ok := r.original[name]; !ok {
+		r.original[name] = r.Fields[name]
+	}
+	r.Fields[name] = value
+}
+
+// Unset clears field value, Airtable clears fields set to null.
+func (r *Record) Unset(name string) {
+	r.Set(name, nil)
+}
+
+// IsDirty reports whether the record has changes not sent to Airtable.
+func (r *Record) IsDirty() bool {
+	return len(r.Changes()) > 0
+}
+
+// Changes returns fields changed with Set since the record was read or saved.
+// Fields set back to the original value (compared with FieldsEqual) are not included,
+// reordered attachments and collaborators are included.
+func (r *Record) Changes() map[string]any {
+	changes := map[string]any{}
+	for name, original := range r.original {
+		if value := r.Fields[name]; fieldChanged(original, value) {
+			changes[name] = value
+		}
+	}
+	return changes
+}
+
+// fieldChanged reports whether the value should be sent to Airtable.
+// Unlike FieldsEqual it takes order of objects with ID into account,
+// since Airtable keeps attachments and collaborators in the written order.
+func fieldChanged(original, value any) bool {
+	if !FieldsEqual(original, value) {
+		return true
+	}
+	idsA, okA := objectIDs(normalizeValue(original))
+	idsB, okB := objectIDs(normalizeValue(value))
+	return okA && okB && !slices.Equal(idsA, idsB)
+}
+
+// objectIDs returns IDs of the list items if all of them are objects with ID.
+func objectIDs(v any) ([]string, bool) {
+	list, ok := v.([]any)
+	if !ok {
+		return nil, false
+	}
+	ids := make([]string, 0, len(list))
+	for _, item := range list {
+		object, ok := item.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		id, ok := object["id"].(string)
+		if !ok {
+			return nil, false
+		}
+		ids = append(ids, id)
+	}
+	return ids, true
+}
+
+// Save sends changed fields to Airtable.
+func (r *Record) Save() error {
+	return r.SaveContext(context.Background())
+}
+
+// SaveContext sends changed fields to Airtable
+// with custom context.
+func (r *Record) SaveContext(ctx context.Context) error {
+	if r.table == nil {
+		return ErrNoTable
+	}
+	changes := r.Changes()
+	if len(changes) == 0 {
+		return nil
+	}
+
+	result, err := r.UpdateRecordPartialContext(ctx, changes)
+	if err != nil {
+		return err
+	}
+
+	r.saved(result)
+
+	return nil
+}
+
+// SaveIfUnchanged re-reads the record before write and returns ErrRecordChanged
+// if any of the changed fields were modified on the server since the record was read.
+func (r *Record) SaveIfUnchanged() error {
+	return r.SaveIfUnchangedContext(context.Background())
+}
+
+// SaveIfUnchangedContext re-reads the record before write
+// with custom context.
+func (r *Record) SaveIfUnchangedContext(ctx context.Context) error {
+	if r.table == nil {
+		return ErrNoTable
+	}
+	changes := r.Changes()
+	if len(changes) == 0 {
+		return nil
+	}
+
+	current, err := r.table.GetRecordContext(withoutCache(ctx), r.ID)
+	if err != nil {
+		return err
+	}
+
+	var conflicts []string
+	for name := range changes {
+		if !FieldsEqual(r.original[name], current.Fields[name]) {
+			conflicts = append(conflicts, name)
+		}
+	}
+	if len(conflicts) > 0 {
+		sort.Strings(conflicts)
+		return fmt.Errorf("%w: fields %v", ErrRecordChanged, conflicts)
+	}
+
+	return r.SaveContext(ctx)
+}
+
+// saved updates record with the server response and resets changes.
+func (r *Record) saved(result *Record) {
+	r.Fields = result.Fields
+	r.CreatedTime = result.CreatedTime
+	r.original = nil
+}
+
+// SaveAll sends changed fields of dirty records in batches of 10.
+func (t *Table) SaveAll(records []*Record) error {
+	return t.SaveAllContext(context.Background(), records)
+}
+
+// SaveAllContext sends changed fields of dirty records in batches of 10
+// with custom context.
+func (t *Table) SaveAllContext(ctx context.Context, records []*Record) error {
+	var dirty []*Record
+	for _, record := range records {
+		if record.IsDirty() {
+			dirty = append(dirty, record)
+		}
+	}
+
+	for start := 0; start < len(dirty); start += MaxBatchSize {
+		batch := dirty[start:min(start+MaxBatchSize, len(dirty))]
+
+		data := &Records{}
+		for _, record := range batch {
+			data.Records = append(data.Records, &Record{ID: record.ID, Fields: record.Changes()})
+		}
+
+		response, err := t.UpdateRecordsPartialContext(ctx, data)
+		if err != nil {
+			return err
+		}
+
+		byID := make(map[string]*Record, len(response.Records))
+		for _, result := range response.Records {
+			byID[result.ID] = result
+		}
+		for _, record := range batch {
+			if result, ok := byID[record.ID]; ok {
+				record.saved(result)
+			}
+		}
+	}
+
+	return nil
+}
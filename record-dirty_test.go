@@ -0,0 +1,153 @@
+// Copyright © 2020 Mike Berezin
+//
+// Use of this source code is governed by an MIT license.
+// Details in the LICENSE file.
+
+package airtable
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// mockPatchServer echoes patched records back and counts requests.
+func mockPatchServer(t *testing.T, requests *[]*Records) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		data := new(Records)
+		if err := json.NewDecoder(r.Body).Decode(data); err != nil {
+			t.Errorf("cannot decode body: %v", err)
+		}
+		*requests = append(*requests, data)
+		_ = json.NewEncoder(rw).Encode(data)
+	}))
+}
+
+func TestRecord_Set(t *testing.T) {
+	record := &Record{Fields: map[string]any{"Field1": "Field1", "Field2": true}}
+	if record.IsDirty() {
+		t.Errorf("record should not be dirty")
+	}
+	record.Set("Field1", "changed")
+	record.Unset("Field2")
+	record.Set("Field3", float64(1))
+	record.Set("Field3", nil)
+	expected := map[string]any{"Field1": "changed", "Field2": nil}
+	if !reflect.DeepEqual(record.Changes(), expected) {
+		t.Errorf("expected changes: %v, but got: %v", expected, record.Changes())
+	}
+	record.Set("Field1", "Field1")
+	record.Set("Field2", true)
+	if record.IsDirty() {
+		t.Errorf("record set back to original values should not be dirty, but was: %v", record.Changes())
+	}
+	if err := record.Save(); !errors.Is(err, ErrNoTable) {
+		t.Errorf("should be ErrNoTable, but was: %v", err)
+	}
+
+	attachments := []any{map[string]any{"id": "att1"}, map[string]any{"id": "att2"}}
+	record = &Record{Fields: map[string]any{"Pictures": attachments}}
+	record.Set("Pictures", []any{attachments[1], attachments[0]})
+	if !record.IsDirty() {
+		t.Errorf("reordered attachments should be a change")
+	}
+	record.Set("Pictures", []any{map[string]any{"id": "att1", "url": "https://example.com/1"}, attachments[1]})
+	if record.IsDirty() {
+		t.Errorf("attachments in the original order should not be a change, but was: %v", record.Changes())
+	}
+}
+
+func TestRecord_Save(t *testing.T) {
+	var requests []*Records
+	record := testRecord(t)
+	record.client.baseURL = mockPatchServer(t, &requests).URL
+
+	if err := record.Save(); err != nil || len(requests) != 0 {
+		t.Errorf("clean record should not be sent, but was: %v, %v", err, requests)
+	}
+
+	record.Set("Field2", false)
+	if err := record.Save(); err != nil {
+		t.Fatalf("must be no error, but was: %v", err)
+	}
+	if len(requests) != 1 || !reflect.DeepEqual(requests[0].Records[0].Fields, map[string]any{"Field2": false}) {
+		t.Errorf("only changed field should be sent, but was: %v", requests[0].Records[0].Fields)
+	}
+	if record.IsDirty() {
+		t.Errorf("saved record should not be dirty")
+	}
+
+	record.client.baseURL = mockErrorResponse(422).URL
+	record.Set("Field1", "changed")
+	if err := record.Save(); err == nil {
+		t.Errorf("there should be an err, but was nil")
+	}
+	if !record.IsDirty() {
+		t.Errorf("record should stay dirty after failed save")
+	}
+}
+
+func TestRecord_SaveIfUnchanged(t *testing.T) {
+	var requests []*Records
+	patchServer := mockPatchServer(t, &requests)
+	getServer := mockResponse("get_record.json")
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			getServer.Config.Handler.ServeHTTP(rw, r)
+			return
+		}
+		patchServer.Config.Handler.ServeHTTP(rw, r)
+	}))
+
+	// server record has Field1 = "Field1"
+	record := testRecord(t)
+	record.client.baseURL = server.URL
+	record.Set("Field1", "changed")
+	if err := record.SaveIfUnchanged(); err != nil {
+		t.Errorf("must be no error, but was: %v", err)
+	}
+	if len(requests) != 1 {
+		t.Errorf("record should be saved, but requests were: %v", requests)
+	}
+
+	record = testRecord(t)
+	record.client.baseURL = server.URL
+	record.Fields["Field1"] = "stale"
+	record.Set("Field1", "changed")
+	if err := record.SaveIfUnchanged(); !errors.Is(err, ErrRecordChanged) {
+		t.Errorf("should be ErrRecordChanged, but was: %v", err)
+	}
+	if len(requests) != 1 {
+		t.Errorf("changed record should not be saved, but requests were: %v", requests)
+	}
+}
+
+func TestTable_SaveAll(t *testing.T) {
+	var requests []*Records
+	table := testTable()
+	table.client.baseURL = mockPatchServer(t, &requests).URL
+
+	var records []*Record
+	for i := 0; i < 25; i++ {
+		record := &Record{ID: string(rune('a' + i)), Fields: map[string]any{"Field1": "Field1"}}
+		if i%2 == 0 {
+			record.Set("Field1", "changed")
+		}
+		records = append(records, record)
+	}
+
+	if err := table.SaveAll(records); err != nil {
+		t.Fatalf("must be no error, but was: %v", err)
+	}
+	if len(requests) != 2 || len(requests[0].Records) != 10 || len(requests[1].Records) != 3 {
+		t.Errorf("13 dirty records should be sent in 2 batches, but was: %v", requests)
+	}
+	for _, record := range records {
+		if record.IsDirty() {
+			t.Errorf("record %s should not be dirty after save", record.ID)
+		}
+	}
+}
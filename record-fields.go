@@ -0,0 +1,80 @@
+// Copyright © 2020 Mike Berezin
+//
+// Use of this source code is governed by an MIT license.
+// Details in the LICENSE file.
+
+package airtable
+
+import (
+	"fmt"
+	"time"
+)
+
+// Typed accessors to record fields.
+// Airtable omits empty fields, so missing field returns zero value without error.
+// Field of other type returns error wrapping ErrNot* converter errors.
+
+// GetString returns text field value.
+func (r *Record) GetString(name string) (string, error) {
+	v, err := ToString(r.Fields[name])
+	return v, fieldError(name, err)
+}
+
+// GetFloat returns number field value.
+func (r *Record) GetFloat(name string) (float64, error) {
+	v, err := ToFloat(r.Fields[name])
+	return v, fieldError(name, err)
+}
+
+// GetInt returns number field value without fractional part.
+func (r *Record) GetInt(name string) (int, error) {
+	v, err := ToInt(r.Fields[name])
+	return v, fieldError(name, err)
+}
+
+// GetBool returns checkbox field value.
+func (r *Record) GetBool(name string) (bool, error) {
+	v, err := ToBool(r.Fields[name])
+	return v, fieldError(name, err)
+}
+
+// GetTime returns date or dateTime field value in UTC.
+func (r *Record) GetTime(name string) (time.Time, error) {
+	field := r.Fields[name]
+	if field == nil {
+		return time.Time{}, nil
+	}
+	if t, err := ToDateTime(field); err == nil {
+		return t.UTC(), nil
+	}
+	d, err := ToDate(field)
+	if err != nil {
+		return time.Time{}, fieldError(name, err)
+	}
+	return d.In(time.UTC), nil
+}
+
+// GetStrings returns multipleSelects field value.
+func (r *Record) GetStrings(name string) ([]string, error) {
+	v, err := ToStrings(r.Fields[name])
+	return v, fieldError(name, err)
+}
+
+// GetLinkedIDs returns IDs of linked records.
+func (r *Record) GetLinkedIDs(name string) ([]string, error) {
+	v, err := ToLinkedIDs(r.Fields[name])
+	return v, fieldError(name, err)
+}
+
+// GetAttachments returns multipleAttachments field value.
+func (r *Record) GetAttachments(name string) ([]FieldAttachmentDetails, error) {
+	v, err := ToAttachments(r.Fields[name])
+	return v, fieldError(name, err)
+}
+
+func fieldError(name string, err error) error {
+	if err == nil {
+		return nil
+	}
+	return fmt.Errorf("field %q: %w", name, err)
+}
@@ -0,0 +1,79 @@
+// Copyright © 2020 Mike Berezin
+//
+// Use of this source code is governed by an MIT license.
+// Details in the LICENSE file.
+
+package airtable
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRecord_Getters(t *testing.T) {
+	record := &Record{
+		Fields: map[string]any{
+			"Name":     "Name",
+			"Count":    float64(3),
+			"Price":    2.5,
+			"Done":     true,
+			"Due":      "2020-04-06T06:00:00.000Z",
+			"Day":      "2020-04-06",
+			"Tags":     []any{"a", "b"},
+			"District": []any{"recK6MZHez0ZvBChZ"},
+			"Pictures": []any{map[string]any{"id": "att1", "url": "https://example.com/test.png"}},
+		},
+	}
+
+	if v, err := record.GetString("Name"); err != nil || v != "Name" {
+		t.Errorf("GetString() = %v, %v", v, err)
+	}
+	if v, err := record.GetInt("Count"); err != nil || v != 3 {
+		t.Errorf("GetInt() = %v, %v", v, err)
+	}
+	if v, err := record.GetFloat("Price"); err != nil || v != 2.5 {
+		t.Errorf("GetFloat() = %v, %v", v, err)
+	}
+	if v, err := record.GetBool("Done"); err != nil || !v {
+		t.Errorf("GetBool() = %v, %v", v, err)
+	}
+	if v, err := record.GetTime("Due"); err != nil || !v.Equal(time.Date(2020, 4, 6, 6, 0, 0, 0, time.UTC)) {
+		t.Errorf("GetTime() = %v, %v", v, err)
+	}
+	if v, err := record.GetTime("Day"); err != nil || !v.Equal(time.Date(2020, 4, 6, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("GetTime() = %v, %v", v, err)
+	}
+	if v, err := record.GetStrings("Tags"); err != nil || !reflect.DeepEqual(v, []string{"a", "b"}) {
+		t.Errorf("GetStrings() = %v, %v", v, err)
+	}
+	if v, err := record.GetLinkedIDs("District"); err != nil || !reflect.DeepEqual(v, []string{"recK6MZHez0ZvBChZ"}) {
+		t.Errorf("GetLinkedIDs() = %v, %v", v, err)
+	}
+	if v, err := record.GetAttachments("Pictures"); err != nil || len(v) != 1 || v[0].Id != "att1" {
+		t.Errorf("GetAttachments() = %v, %v", v, err)
+	}
+
+	// omitted fields are zero values
+	if v, err := record.GetBool("Missing"); err != nil || v {
+		t.Errorf("GetBool() = %v, %v", v, err)
+	}
+	if v, err := record.GetTime("Missing"); err != nil || !v.IsZero() {
+		t.Errorf("GetTime() = %v, %v", v, err)
+	}
+
+	// wrong types are typed errors
+	if _, err := record.GetInt("Name"); !errors.Is(err, ErrNotNumber) {
+		t.Errorf("GetInt() error = %v, want %v", err, ErrNotNumber)
+	}
+	if _, err := record.GetInt("Price"); !errors.Is(err, ErrNotInteger) {
+		t.Errorf("GetInt() error = %v, want %v", err, ErrNotInteger)
+	}
+	if _, err := record.GetTime("Name"); !errors.Is(err, ErrNotDateTime) {
+		t.Errorf("GetTime() error = %v, want %v", err, ErrNotDateTime)
+	}
+	if _, err := record.GetString("Done"); !errors.Is(err, ErrNotString) {
+		t.Errorf("GetString() error = %v, want %v", err, ErrNotString)
+	}
+}
@@ -14,10 +14,15 @@ import (
 type Record struct {
 	client      *Client
 	table       *Table
+	original    map[string]any
 	ID          string         `json:"id,omitempty"`
 	Fields      map[string]any `json:"fields"`
 	CreatedTime string         `json:"createdTime,omitempty"`
 	Deleted     bool           `json:"deleted,omitempty"`
+	// CommentCount is returned only when requested with RecordMetadataCommentCount.
+	CommentCount int `json:"commentCount,omitempty"`
+	// Linked records of link fields, filled when requested with GetRecordsConfig.Expand.
+	Linked map[string][]*Record `json:"-"`
 
 	// The Airtable API will perform best-effort automatic data conversion
 	// from string values if the typecast parameter is passed in.
@@ -35,9 +40,23 @@ func (t *Table) GetRecord(recordID string) (*Record, error) {
 // GetRecordContext get record from table
 // with custom context
 func (t *Table) GetRecordContext(ctx context.Context, recordID string) (*Record, error) {
+	return t.GetRecordWithParamsContext(ctx, recordID, url.Values{})
+}
+
+// GetRecordWithParams get record from table with url values params.
+func (t *Table) GetRecordWithParams(recordID string, params url.Values) (*Record, error) {
+	return t.GetRecordWithParamsContext(context.Background(), recordID, params)
+}
+
+// GetRecordWithParamsContext get record from table with url values params
+// with custom context
+func (t *Table) GetRecordWithParamsContext(ctx context.Context, recordID string, params url.Values) (*Record, error) {
 	result := new(Record)
 
-	err := t.client.get(ctx, t.dbName, t.tableName, recordID, url.Values{}, result)
+	key := recordCacheKey(t.dbName, t.tableName, recordID, params)
+	err := t.client.cached(ctx, t.dbName, key, t.client.cacheTTL(cacheRecord), result, func() error {
+		return t.client.get(ctx, t.dbName, t.tableName, recordID, params, result)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,96 @@
+// Copyright © 2020 Mike Berezin
+//
+// Use of this source code is governed by an MIT license.
+// Details in the LICENSE file.
+
+package airtable
+
+import (
+	"context"
+	"fmt"
+)
+
+// fieldRequest field to create or update, IDs are assigned by Airtable.
+type fieldRequest struct {
+	Name        string         `json:"name,omitempty"`
+	Type        string         `json:"type,omitempty"`
+	Description string         `json:"description,omitempty"`
+	Options     map[string]any `json:"options,omitempty"`
+}
+
+type tableRequest struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	Fields      []*fieldRequest `json:"fields"`
+}
+
+func newFieldRequest(field *Field) *fieldRequest {
+	return &fieldRequest{
+		Name:        field.Name,
+		Type:        field.Type,
+		Description: field.Description,
+		Options:     field.Options,
+	}
+}
+
+// CreateTable creates table with the name, description and fields of the schema,
+// the first field becomes the primary field. IDs of the schema are ignored.
+// https://airtable.com/developers/web/api/create-table
+func (b *BaseConfig) CreateTable(table *TableSchema) (*TableSchema, error) {
+	return b.CreateTableContext(context.Background(), table)
+}
+
+// CreateTableContext creates table with custom context.
+func (b *BaseConfig) CreateTableContext(ctx context.Context, table *TableSchema) (*TableSchema, error) {
+	request := &tableRequest{
+		Name:        table.Name,
+		Description: table.Description,
+		Fields:      make([]*fieldRequest, 0, len(table.Fields)),
+	}
+	for _, field := range table.Fields {
+		request.Fields = append(request.Fields, newFieldRequest(field))
+	}
+
+	created := new(TableSchema)
+	err := b.client.post(ctx, "meta/bases", b.dbId+"/tables", request, created)
+	if err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
+
+// CreateField creates field in the table, the ID of the field is ignored.
+// https://airtable.com/developers/web/api/create-field
+func (b *BaseConfig) CreateField(tableID string, field *Field) (*Field, error) {
+	return b.CreateFieldContext(context.Background(), tableID, field)
+}
+
+// CreateFieldContext creates field in the table with custom context.
+func (b *BaseConfig) CreateFieldContext(ctx context.Context, tableID string, field *Field) (*Field, error) {
+	created := new(Field)
+	err := b.client.post(ctx, "meta/bases", fmt.Sprintf("%s/tables/%s/fields", b.dbId, tableID), newFieldRequest(field), created)
+	if err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
+
+// UpdateField changes name and description of the field.
+// https://airtable.com/developers/web/api/update-field
+func (b *BaseConfig) UpdateField(tableID, fieldID, name, description string) (*Field, error) {
+	return b.UpdateFieldContext(context.Background(), tableID, fieldID, name, description)
+}
+
+// UpdateFieldContext changes name and description of the field with custom context.
+func (b *BaseConfig) UpdateFieldContext(ctx context.Context, tableID, fieldID, name, description string) (*Field, error) {
+	updated := new(Field)
+	request := &fieldRequest{Name: name, Description: description}
+	err := b.client.patch(ctx, "meta/bases", fmt.Sprintf("%s/tables/%s/fields/%s", b.dbId, tableID, fieldID), request, updated)
+	if err != nil {
+		return nil, err
+	}
+
+	return updated, nil
+}
@@ -0,0 +1,94 @@
+// Copyright © 2020 Mike Berezin
+//
+// Use of this source code is governed by an MIT license.
+// Details in the LICENSE file.
+
+package airtable
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// mockEchoServer answers with the request body and records method, path and body.
+func mockEchoServer(t *testing.T, requests *[]string) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		b, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+		}
+		*requests = append(*requests, r.Method+" "+r.URL.Path+" "+string(b))
+		_, _ = rw.Write(b)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestBaseConfig_CreateTable(t *testing.T) {
+	var requests []string
+	client := testClient()
+	client.baseURL = mockEchoServer(t, &requests).URL
+
+	table, err := client.GetBaseSchema("appBase").CreateTable(&TableSchema{
+		ID:   "tblOld",
+		Name: "Apartments",
+		Fields: []*Field{
+			{ID: "fldOld", Name: "Name", Type: "singleLineText"},
+			{Name: "Rooms", Type: "number", Options: map[string]any{"precision": float64(0)}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("there should not be an err, but was: %v", err)
+	}
+	if table.Name != "Apartments" || len(table.Fields) != 2 || table.Fields[1].Options["precision"] != float64(0) {
+		t.Errorf("unexpected table: %+v", table)
+	}
+
+	expected := `POST /meta/bases/appBase/tables {"name":"Apartments","fields":[{"name":"Name","type":"singleLineText"},{"name":"Rooms","type":"number","options":{"precision":0}}]}`
+	if len(requests) != 1 || requests[0] != expected {
+		t.Errorf("expected request\n%s\nbut was\n%v", expected, requests)
+	}
+
+	client.baseURL = mockErrorResponse(422).URL
+	if _, err := client.GetBaseSchema("appBase").CreateTable(&TableSchema{Name: "Apartments"}); err == nil {
+		t.Errorf("there should be an err, but was nil")
+	}
+}
+
+func TestBaseConfig_CreateUpdateField(t *testing.T) {
+	var requests []string
+	client := testClient()
+	client.baseURL = mockEchoServer(t, &requests).URL
+	schema := client.GetBaseSchema("appBase")
+
+	field, err := schema.CreateField("tblApartments", &Field{
+		Name:    "District",
+		Type:    "multipleRecordLinks",
+		Options: map[string]any{"linkedTableId": "tblDistricts"},
+	})
+	if err != nil {
+		t.Fatalf("there should not be an err, but was: %v", err)
+	}
+	if field.Name != "District" || field.Options["linkedTableId"] != "tblDistricts" {
+		t.Errorf("unexpected field: %+v", field)
+	}
+
+	field, err = schema.UpdateField("tblDistricts", "fldApartments", "Apartments", "")
+	if err != nil {
+		t.Fatalf("there should not be an err, but was: %v", err)
+	}
+	if field.Name != "Apartments" {
+		t.Errorf("unexpected field: %+v", field)
+	}
+
+	expected := []string{
+		`POST /meta/bases/appBase/tables/tblApartments/fields {"name":"District","type":"multipleRecordLinks","options":{"linkedTableId":"tblDistricts"}}`,
+		`PATCH /meta/bases/appBase/tables/tblDistricts/fields/fldApartments {"name":"Apartments"}`,
+	}
+	if !reflect.DeepEqual(requests, expected) {
+		t.Errorf("expected requests\n%q\nbut was\n%q", expected, requests)
+	}
+}
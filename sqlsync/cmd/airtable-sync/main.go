@@ -0,0 +1,75 @@
+// Copyright © 2020 Mike Berezin
+//
+// Use of this source code is governed by an MIT license.
+// Details in the LICENSE file.
+
+// Command airtable-sync mirrors tables of a base into a SQLite database.
+//
+//	airtable-sync -base appXXX [-tables Table1,Table2] [-full] mirror.db
+//
+// API key is read from -key flag or AIRTABLE_API_KEY environment variable.
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/mehanizm/airtable"
+	"github.com/mehanizm/airtable/sqlsync"
+)
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	apiKey := flag.String("key", os.Getenv("AIRTABLE_API_KEY"), "Airtable API key, AIRTABLE_API_KEY by default")
+	base := flag.String("base", "", "base ID")
+	tables := flag.String("tables", "", "comma separated tables to sync, all tables by default")
+	full := flag.Bool("full", false, "read all records even if the tables were synced before, refreshes computed fields")
+	flag.Parse()
+
+	if *apiKey == "" {
+		return fmt.Errorf("API key is required")
+	}
+	if *base == "" {
+		return fmt.Errorf("base is required")
+	}
+	if flag.NArg() != 1 {
+		return fmt.Errorf("SQLite database file is required")
+	}
+
+	db, err := sql.Open("sqlite3", flag.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	opts := sqlsync.Options{Full: *full}
+	if *tables != "" {
+		opts.Tables = strings.Split(*tables, ",")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	result, err := sqlsync.Sync(ctx, airtable.NewClient(*apiKey), *base, db, opts)
+	if result != nil {
+		for _, table := range result.Tables {
+			kind := "incremental"
+			if table.Full {
+				kind = "full"
+			}
+			fmt.Printf("%s: %s, %d upserted, %d deleted\n", table.Name, kind, table.Upserted, table.Deleted)
+		}
+	}
+	return err
+}
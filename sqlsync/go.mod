@@ -0,0 +1,12 @@
+module github.com/mehanizm/airtable/sqlsync
+
+go 1.23.0
+
+require (
+	github.com/mattn/go-sqlite3 v1.14.33
+	github.com/mehanizm/airtable v0.0.0
+)
+
+require golang.org/x/time v0.8.0 // indirect
+
+replace github.com/mehanizm/airtable => ../
@@ -0,0 +1,285 @@
+// Copyright © 2020 Mike Berezin
+//
+// Use of this source code is governed by an MIT license.
+// Details in the LICENSE file.
+
+package sqlsync
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/mehanizm/airtable"
+)
+
+const (
+	// idColumn and createdTimeColumn are prefixed
+	// not to collide with field names like "ID".
+	idColumn          = "_id"
+	createdTimeColumn = "_created_time"
+
+	stateTable = "_airtable_sync"
+
+	// joinTablePrefix separates join tables from the tables of the base.
+	joinTablePrefix = "_links_"
+)
+
+// column of the table mirroring a field.
+type column struct {
+	field *airtable.Field
+	typ   string
+}
+
+// join table mirroring a linked records or multiple selects field
+// with a row per linked record or selected option.
+type join struct {
+	field *airtable.Field
+	table string
+	// valueColumn is "linked_id" for links and "value" for selects.
+	valueColumn string
+}
+
+// mirror SQLite tables of the Airtable table.
+type mirror struct {
+	schema  *airtable.TableSchema
+	columns []column
+	joins   []join
+}
+
+func newMirror(schema *airtable.TableSchema) *mirror {
+	m := &mirror{schema: schema}
+	for _, field := range schema.Fields {
+		switch field.Type {
+		case "multipleRecordLinks":
+			m.joins = append(m.joins, join{field: field, table: JoinTable(schema.Name, field.Name), valueColumn: "linked_id"})
+		case "multipleSelects":
+			m.joins = append(m.joins, join{field: field, table: JoinTable(schema.Name, field.Name), valueColumn: "value"})
+		default:
+			m.columns = append(m.columns, column{field: field, typ: ColumnType(field)})
+		}
+	}
+	return m
+}
+
+// JoinTable returns name of the join table of the linked records
+// or multiple selects field, e.g. "_links_Apartments_District".
+func JoinTable(table, field string) string {
+	return joinTablePrefix + table + "_" + field
+}
+
+// ColumnType returns SQLite column type of the field:
+// INTEGER for checkboxes and counters, REAL for numbers and TEXT for others.
+// Formulas and rollups are typed by their result, values which are not
+// scalars (attachments, collaborators, lookups) are stored as JSON text.
+func ColumnType(field *airtable.Field) string {
+	switch field.Type {
+	case "checkbox", "autoNumber", "count", "rating":
+		return "INTEGER"
+	case "number", "percent", "currency", "duration":
+		return "REAL"
+	case "formula", "rollup":
+		if result, ok := field.Options["result"].(map[string]any); ok {
+			if typ, ok := result["type"].(string); ok {
+				return ColumnType(&airtable.Field{Type: typ})
+			}
+		}
+	}
+	return "TEXT"
+}
+
+// columnValue converts cell value to SQLite value.
+func columnValue(value any) (any, error) {
+	switch v := value.(type) {
+	case nil, string, float64:
+		return v, nil
+	case bool:
+		if v {
+			return 1, nil
+		}
+		return 0, nil
+	}
+	b, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// quote quotes SQL identifier.
+func quote(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
+
+// changes reports whether create would create tables or add columns,
+// so existing records are missing from them.
+func (m *mirror) changes(ctx context.Context, q queryer) (bool, error) {
+	existing, err := tableColumns(ctx, q, m.schema.Name)
+	if err != nil {
+		return false, err
+	}
+	for _, c := range m.columns {
+		if !existing[strings.ToLower(c.field.Name)] {
+			return true, nil
+		}
+	}
+	for _, j := range m.joins {
+		columns, err := tableColumns(ctx, q, j.table)
+		if err != nil {
+			return false, err
+		}
+		if len(columns) == 0 {
+			return true, nil
+		}
+	}
+	return len(existing) == 0, nil
+}
+
+// create creates tables of the mirror or adds columns of new fields.
+// Columns of removed fields are kept.
+func (m *mirror) create(ctx context.Context, tx *sql.Tx) error {
+	existing, err := tableColumns(ctx, tx, m.schema.Name)
+	if err != nil {
+		return err
+	}
+	if len(existing) == 0 {
+		definitions := []string{
+			quote(idColumn) + " TEXT PRIMARY KEY",
+			quote(createdTimeColumn) + " TEXT",
+		}
+		for _, c := range m.columns {
+			definitions = append(definitions, quote(c.field.Name)+" "+c.typ)
+		}
+		query := fmt.Sprintf("CREATE TABLE %s (%s)", quote(m.schema.Name), strings.Join(definitions, ", "))
+		if _, err := tx.ExecContext(ctx, query); err != nil {
+			return fmt.Errorf("cannot create table %q: %w", m.schema.Name, err)
+		}
+	} else {
+		for _, c := range m.columns {
+			if existing[strings.ToLower(c.field.Name)] {
+				continue
+			}
+			query := fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", quote(m.schema.Name), quote(c.field.Name), c.typ)
+			if _, err := tx.ExecContext(ctx, query); err != nil {
+				return fmt.Errorf("cannot add column %q: %w", c.field.Name, err)
+			}
+		}
+	}
+
+	for _, j := range m.joins {
+		query := fmt.Sprintf(
+			"CREATE TABLE IF NOT EXISTS %s (record_id TEXT NOT NULL, %s TEXT NOT NULL, position INTEGER NOT NULL, PRIMARY KEY (record_id, position))",
+			quote(j.table), j.valueColumn,
+		)
+		if _, err := tx.ExecContext(ctx, query); err != nil {
+			return fmt.Errorf("cannot create table %q: %w", j.table, err)
+		}
+		index := fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s (%s)", quote(j.table+"_"+j.valueColumn), quote(j.table), j.valueColumn)
+		if _, err := tx.ExecContext(ctx, index); err != nil {
+			return fmt.Errorf("cannot create index of %q: %w", j.table, err)
+		}
+	}
+	return nil
+}
+
+// queryer is *sql.DB or *sql.Tx.
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+// tableColumns returns lower case names of the table columns,
+// empty if there is no table.
+func tableColumns(ctx context.Context, q queryer, table string) (map[string]bool, error) {
+	rows, err := q.QueryContext(ctx, "SELECT name FROM pragma_table_info(?)", table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	columns := map[string]bool{}
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		columns[strings.ToLower(name)] = true
+	}
+	return columns, rows.Err()
+}
+
+// upsert replaces the record row and its join rows.
+func (m *mirror) upsert(ctx context.Context, tx *sql.Tx, record *airtable.Record) error {
+	names := []string{quote(idColumn), quote(createdTimeColumn)}
+	values := []any{record.ID, record.CreatedTime}
+	for _, c := range m.columns {
+		value, err := columnValue(record.Fields[c.field.Name])
+		if err != nil {
+			return fmt.Errorf("record %s field %q: %w", record.ID, c.field.Name, err)
+		}
+		names = append(names, quote(c.field.Name))
+		values = append(values, value)
+	}
+	query := fmt.Sprintf(
+		"INSERT OR REPLACE INTO %s (%s) VALUES (%s)",
+		quote(m.schema.Name), strings.Join(names, ", "), strings.TrimSuffix(strings.Repeat("?, ", len(names)), ", "),
+	)
+	if _, err := tx.ExecContext(ctx, query, values...); err != nil {
+		return fmt.Errorf("cannot write record %s: %w", record.ID, err)
+	}
+
+	for _, j := range m.joins {
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE record_id = ?", quote(j.table)), record.ID); err != nil {
+			return err
+		}
+		items, _ := record.Fields[j.field.Name].([]any)
+		for i, item := range items {
+			value, ok := item.(string)
+			if !ok {
+				continue
+			}
+			query := fmt.Sprintf("INSERT INTO %s (record_id, %s, position) VALUES (?, ?, ?)", quote(j.table), j.valueColumn)
+			if _, err := tx.ExecContext(ctx, query, record.ID, value, i); err != nil {
+				return fmt.Errorf("cannot write record %s field %q: %w", record.ID, j.field.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// delete deletes rows of records which are not in ids.
+// Returns number of deleted records.
+func (m *mirror) delete(ctx context.Context, tx *sql.Tx, ids map[string]bool) (int, error) {
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf("SELECT %s FROM %s", quote(idColumn), quote(m.schema.Name)))
+	if err != nil {
+		return 0, err
+	}
+	var deleted []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		if !ids[id] {
+			deleted = append(deleted, id)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, id := range deleted {
+		query := fmt.Sprintf("DELETE FROM %s WHERE %s = ?", quote(m.schema.Name), quote(idColumn))
+		if _, err := tx.ExecContext(ctx, query, id); err != nil {
+			return 0, err
+		}
+		for _, j := range m.joins {
+			if _, err := tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE record_id = ?", quote(j.table)), id); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return len(deleted), nil
+}
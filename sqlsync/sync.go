@@ -0,0 +1,229 @@
+// Copyright © 2020 Mike Berezin
+//
+// Use of this source code is governed by an MIT license.
+// Details in the LICENSE file.
+
+// Package sqlsync mirrors tables of an Airtable base into a SQLite database.
+//
+// Every table is mirrored to a SQLite table with the same name, "_id" and
+// "_created_time" columns and a column per field typed by ColumnType.
+// Linked records and multiple selects fields are mirrored to join tables
+// prefixed with "_links_", see JoinTable, with record_id, linked_id or value and position columns.
+// Time of the last sync of every table is kept in "_airtable_sync" table.
+//
+// The first sync of a table reads all records, next ones read only records
+// created or modified since the last sync with LAST_MODIFIED_TIME() filter
+// and diff record IDs to delete removed records. A table is read fully again
+// when its mirror gets new columns or tables, e.g. after a field is added or renamed
+// or the table is renamed, so old records get values of the new columns.
+//
+// Values of computed fields (formulas, lookups, rollups, etc.) change without
+// changing LAST_MODIFIED_TIME() of their records, so incremental syncs
+// leave them stale. Run a sync with Options.Full periodically to refresh them.
+//
+// The package uses database/sql with SQLite syntax, the driver,
+// e.g. github.com/mattn/go-sqlite3, is registered by the caller.
+package sqlsync
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mehanizm/airtable"
+)
+
+// ErrNameConflict returned when two tables of the mirror would have the same name.
+var ErrNameConflict = errors.New("mirror table name conflict")
+
+// clockSkew is subtracted from the last sync time in incremental filter,
+// records are upserted so reading some of them again is harmless.
+const clockSkew = time.Minute
+
+// Options of the sync.
+type Options struct {
+	// Tables names or IDs to sync, all tables if empty.
+	Tables []string
+	// Full reads all records even if the tables were synced before,
+	// to refresh values of computed fields.
+	Full bool
+}
+
+// Result of the sync.
+type Result struct {
+	Tables []*TableResult
+}
+
+// TableResult of the table sync.
+type TableResult struct {
+	ID   string
+	Name string
+	// Full reports whether all records were read.
+	Full bool
+	// Upserted number of created or updated records.
+	Upserted int
+	// Deleted number of records removed from the mirror.
+	Deleted int
+}
+
+// Sync creates or updates SQLite tables from the base schema
+// and syncs records of the tables. Records of a table are read first
+// and written in a transaction, so a failed sync leaves the mirror
+// of the table as it was and the database is not locked while reading.
+func Sync(ctx context.Context, client *airtable.Client, baseID string, db *sql.DB, opts Options) (*Result, error) {
+	schema, err := client.GetBaseSchema(baseID).GetTablesContext(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get base schema: %w", err)
+	}
+	for _, name := range opts.Tables {
+		if schema.Table(name) == nil {
+			return nil, fmt.Errorf("%w: %q", airtable.ErrTableNotFound, name)
+		}
+	}
+	if err := checkNames(schema); err != nil {
+		return nil, err
+	}
+	query := fmt.Sprintf(
+		"CREATE TABLE IF NOT EXISTS %s (table_id TEXT PRIMARY KEY, table_name TEXT NOT NULL, synced_time TEXT NOT NULL)",
+		quote(stateTable),
+	)
+	if _, err := db.ExecContext(ctx, query); err != nil {
+		return nil, fmt.Errorf("cannot create sync state table: %w", err)
+	}
+
+	result := new(Result)
+	for _, table := range schema.Tables {
+		if len(opts.Tables) > 0 && !slices.Contains(opts.Tables, table.ID) && !slices.Contains(opts.Tables, table.Name) {
+			continue
+		}
+		tableResult, err := syncTable(ctx, client.GetTable(baseID, table.ID), db, table, opts.Full)
+		if err != nil {
+			return result, fmt.Errorf("table %q: %w", table.Name, err)
+		}
+		result.Tables = append(result.Tables, tableResult)
+	}
+	return result, nil
+}
+
+func syncTable(ctx context.Context, table *airtable.Table, db *sql.DB, schema *airtable.TableSchema, full bool) (*TableResult, error) {
+	result := &TableResult{ID: schema.ID, Name: schema.Name, Full: full}
+	start := time.Now().UTC()
+	m := newMirror(schema)
+
+	var lastSync time.Time
+	if !full {
+		var err error
+		lastSync, err = syncedTime(ctx, db, schema.ID)
+		if err != nil {
+			return nil, err
+		}
+		changes, err := m.changes(ctx, db)
+		if err != nil {
+			return nil, err
+		}
+		result.Full = lastSync.IsZero() || changes
+	}
+
+	grc := table.GetRecords()
+	if !result.Full {
+		grc = grc.WithFilterFormula(airtable.ModifiedSince(lastSync.Add(-clockSkew)))
+	}
+	var records []*airtable.Record
+	ids := map[string]bool{}
+	for record, err := range grc.Iterate(ctx) {
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, record)
+		ids[record.ID] = true
+	}
+	if !result.Full {
+		// only IDs of all records are needed to find deleted ones
+		var err error
+		ids, err = recordIDs(ctx, table, schema)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := m.create(ctx, tx); err != nil {
+		return nil, err
+	}
+	for _, record := range records {
+		if err := m.upsert(ctx, tx, record); err != nil {
+			return nil, err
+		}
+		result.Upserted++
+	}
+	if result.Deleted, err = m.delete(ctx, tx, ids); err != nil {
+		return nil, err
+	}
+
+	query := fmt.Sprintf("INSERT OR REPLACE INTO %s (table_id, table_name, synced_time) VALUES (?, ?, ?)", quote(stateTable))
+	if _, err := tx.ExecContext(ctx, query, schema.ID, schema.Name, start.Format(time.RFC3339)); err != nil {
+		return nil, err
+	}
+	return result, tx.Commit()
+}
+
+// checkNames returns ErrNameConflict if names of the mirror tables
+// of the base collide, SQLite names are case-insensitive.
+func checkNames(schema *airtable.Tables) error {
+	names := map[string]string{strings.ToLower(stateTable): stateTable}
+	for _, table := range schema.Tables {
+		m := newMirror(table)
+		tableNames := []string{table.Name}
+		for _, j := range m.joins {
+			tableNames = append(tableNames, j.table)
+		}
+		for _, name := range tableNames {
+			key := strings.ToLower(name)
+			if other, ok := names[key]; ok {
+				return fmt.Errorf("%w: %q and %q", ErrNameConflict, other, name)
+			}
+			names[key] = name
+		}
+	}
+	return nil
+}
+
+// syncedTime returns time of the last sync of the table, zero if there was none.
+func syncedTime(ctx context.Context, db *sql.DB, tableID string) (time.Time, error) {
+	var synced string
+	query := fmt.Sprintf("SELECT synced_time FROM %s WHERE table_id = ?", quote(stateTable))
+	err := db.QueryRowContext(ctx, query, tableID).Scan(&synced)
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Parse(time.RFC3339, synced)
+}
+
+// recordIDs returns IDs of all records of the table
+// reading only the primary field.
+func recordIDs(ctx context.Context, table *airtable.Table, schema *airtable.TableSchema) (map[string]bool, error) {
+	records := table.GetRecords()
+	if primary := schema.Field(schema.PrimaryFieldID); primary != nil {
+		records = records.ReturnFields(primary.Name)
+	}
+	ids := map[string]bool{}
+	for record, err := range records.Iterate(ctx) {
+		if err != nil {
+			return nil, err
+		}
+		ids[record.ID] = true
+	}
+	return ids, nil
+}
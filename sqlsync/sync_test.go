@@ -0,0 +1,317 @@
+// Copyright © 2020 Mike Berezin
+//
+// Use of this source code is governed by an MIT license.
+// Details in the LICENSE file.
+
+package sqlsync
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/mehanizm/airtable"
+	"github.com/mehanizm/airtable/internal/airtabletest"
+)
+
+// fakeRecord record of the fake server with its modification time.
+type fakeRecord struct {
+	record   *airtable.Record
+	modified time.Time
+}
+
+// fakeAirtable in-memory Airtable serving schema and records of one base
+// filtered by airtable.ModifiedSince formula.
+type fakeAirtable struct {
+	t       *testing.T
+	mu      sync.Mutex
+	schema  *airtable.Tables
+	records map[string][]*fakeRecord
+	queries []url.Values
+}
+
+var sinceRegexp = regexp.MustCompile(`DATETIME_PARSE\('([^']+)'\)`)
+
+func newFakeAirtable(t *testing.T) (*fakeAirtable, *airtable.Client) {
+	old := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	fake := &fakeAirtable{
+		t: t,
+		schema: &airtable.Tables{Tables: []*airtable.TableSchema{
+			{
+				ID: "tblApartments", Name: "Apartments", PrimaryFieldID: "fldName",
+				Fields: []*airtable.Field{
+					{ID: "fldName", Name: "Name", Type: "singleLineText"},
+					{ID: "fldRooms", Name: "Rooms", Type: "number"},
+					{ID: "fldRented", Name: "Rented", Type: "checkbox"},
+					{ID: "fldFeatures", Name: "Features", Type: "multipleSelects"},
+					{ID: "fldDistrict", Name: "District", Type: "multipleRecordLinks", Options: map[string]any{"linkedTableId": "tblDistricts"}},
+					{ID: "fldPictures", Name: "Pictures", Type: "multipleAttachments"},
+					{ID: "fldTotal", Name: "Total", Type: "formula", Options: map[string]any{"result": map[string]any{"type": "currency"}}},
+				},
+			},
+			{
+				ID: "tblDistricts", Name: "Districts", PrimaryFieldID: "fldDistrictName",
+				Fields: []*airtable.Field{{ID: "fldDistrictName", Name: "Name", Type: "singleLineText"}},
+			},
+		}},
+		records: map[string][]*fakeRecord{
+			"tblApartments": {
+				{modified: old, record: &airtable.Record{ID: "recA1", CreatedTime: "2024-01-01T00:00:00.000Z", Fields: map[string]any{
+					"Name": "Loft", "Rooms": float64(2), "Rented": true, "Features": []any{"Balcony", "Parking"},
+					"District": []any{"recD1"}, "Total": 1500.5,
+					"Pictures": []any{map[string]any{"id": "att1", "url": "https://example.com/loft.png"}},
+				}}},
+				{modified: old, record: &airtable.Record{ID: "recA2", CreatedTime: "2024-01-01T00:00:00.000Z", Fields: map[string]any{
+					"Name": "Studio", "District": []any{"recD1"},
+				}}},
+			},
+			"tblDistricts": {
+				{modified: old, record: &airtable.Record{ID: "recD1", CreatedTime: "2024-01-01T00:00:00.000Z", Fields: map[string]any{"Name": "Center"}}},
+			},
+		},
+	}
+	client, _ := airtabletest.NewClient(t, fake)
+	return fake, client
+}
+
+func (f *fakeAirtable) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	reply := func(v any) { airtabletest.Reply(f.t, w, v) }
+	switch {
+	case parts[0] == "meta":
+		reply(f.schema)
+
+	case len(parts) == 2 && r.Method == http.MethodGet:
+		query := r.URL.Query()
+		f.queries = append(f.queries, query)
+		var since time.Time
+		if match := sinceRegexp.FindStringSubmatch(query.Get("filterByFormula")); match != nil {
+			var err error
+			if since, err = time.Parse(time.RFC3339, match[1]); err != nil {
+				f.t.Error(err)
+			}
+		}
+		var records []*airtable.Record
+		for _, r := range f.records[parts[1]] {
+			created, _ := time.Parse(time.RFC3339, r.record.CreatedTime)
+			if created.After(since) || r.modified.After(since) {
+				records = append(records, r.record)
+			}
+		}
+		// pages of 2 records
+		reply(airtabletest.Page(query, records, 2))
+
+	default:
+		f.t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		http.NotFound(w, r)
+	}
+}
+
+func openDB(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "mirror.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func queryRows(t *testing.T, db *sql.DB, query string) [][]any {
+	t.Helper()
+	rows, err := db.Query(query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	columns, _ := rows.Columns()
+	var result [][]any
+	for rows.Next() {
+		row := make([]any, len(columns))
+		pointers := make([]any, len(columns))
+		for i := range row {
+			pointers[i] = &row[i]
+		}
+		if err := rows.Scan(pointers...); err != nil {
+			t.Fatal(err)
+		}
+		result = append(result, row)
+	}
+	return result
+}
+
+func TestSync(t *testing.T) {
+	fake, client := newFakeAirtable(t)
+	db := openDB(t)
+	ctx := context.Background()
+
+	result, err := Sync(ctx, client, "appXXX", db, Options{})
+	if err != nil {
+		t.Fatalf("must be no error, but was: %v", err)
+	}
+	expected := []*TableResult{
+		{ID: "tblApartments", Name: "Apartments", Full: true, Upserted: 2},
+		{ID: "tblDistricts", Name: "Districts", Full: true, Upserted: 1},
+	}
+	if !reflect.DeepEqual(result.Tables, expected) {
+		t.Errorf("expected %+v, but was %+v", expected, result.Tables)
+	}
+
+	rows := queryRows(t, db, `SELECT "_id", "Name", "Rooms", "Rented", "Pictures", "Total" FROM "Apartments" ORDER BY "_id"`)
+	expectedRows := [][]any{
+		{"recA1", "Loft", 2.0, int64(1), `[{"id":"att1","url":"https://example.com/loft.png"}]`, 1500.5},
+		{"recA2", "Studio", nil, nil, nil, nil},
+	}
+	if !reflect.DeepEqual(rows, expectedRows) {
+		t.Errorf("expected %v, but was %v", expectedRows, rows)
+	}
+	rows = queryRows(t, db, `
+		SELECT "Districts"."Name", "District".position FROM "Apartments"
+		JOIN "_links_Apartments_District" "District" ON record_id = "Apartments"."_id"
+		JOIN "Districts" ON "Districts"."_id" = linked_id
+		WHERE "Apartments"."_id" = 'recA2'`)
+	if !reflect.DeepEqual(rows, [][]any{{"Center", int64(0)}}) {
+		t.Errorf("links should be joined, but were: %v", rows)
+	}
+	rows = queryRows(t, db, `SELECT value FROM "_links_Apartments_Features" WHERE record_id = 'recA1' ORDER BY position`)
+	if !reflect.DeepEqual(rows, [][]any{{"Balcony"}, {"Parking"}}) {
+		t.Errorf("unexpected features: %v", rows)
+	}
+	rows = queryRows(t, db, `SELECT name, type FROM pragma_table_info('Apartments') WHERE name IN ('Rooms', 'Rented', 'Total', 'Features')`)
+	if !reflect.DeepEqual(rows, [][]any{{"Rooms", "REAL"}, {"Rented", "INTEGER"}, {"Total", "REAL"}}) {
+		t.Errorf("unexpected column types: %v", rows)
+	}
+
+	// modify, create and delete records
+	fake.mu.Lock()
+	apartments := fake.records["tblApartments"]
+	apartments[0].modified = time.Now()
+	apartments[0].record.Fields["Features"] = []any{"Garden"}
+	apartments = append(apartments[:1], &fakeRecord{record: &airtable.Record{
+		ID: "recA3", CreatedTime: time.Now().UTC().Format(time.RFC3339), Fields: map[string]any{"Name": "House"},
+	}})
+	fake.records["tblApartments"] = apartments
+	fake.queries = nil
+	fake.mu.Unlock()
+
+	result, err = Sync(ctx, client, "appXXX", db, Options{Tables: []string{"Apartments"}})
+	if err != nil {
+		t.Fatalf("must be no error, but was: %v", err)
+	}
+	expected = []*TableResult{{ID: "tblApartments", Name: "Apartments", Upserted: 2, Deleted: 1}}
+	if !reflect.DeepEqual(result.Tables, expected) {
+		t.Errorf("expected %+v, but was %+v", expected, result.Tables)
+	}
+	if len(fake.queries) != 2 || !strings.Contains(fake.queries[0].Get("filterByFormula"), "LAST_MODIFIED_TIME()") ||
+		fake.queries[1].Get("fields[]") != "Name" {
+		t.Errorf("should read modified records and IDs, but queries were: %v", fake.queries)
+	}
+
+	rows = queryRows(t, db, `SELECT "_id", "Name" FROM "Apartments" ORDER BY "_id"`)
+	if !reflect.DeepEqual(rows, [][]any{{"recA1", "Loft"}, {"recA3", "House"}}) {
+		t.Errorf("unexpected records: %v", rows)
+	}
+	rows = queryRows(t, db, `SELECT record_id, value FROM "_links_Apartments_Features" ORDER BY record_id, position`)
+	if !reflect.DeepEqual(rows, [][]any{{"recA1", "Garden"}}) {
+		t.Errorf("unexpected features: %v", rows)
+	}
+	rows = queryRows(t, db, `SELECT record_id FROM "_links_Apartments_District"`)
+	if !reflect.DeepEqual(rows, [][]any{{"recA1"}}) {
+		t.Errorf("links of deleted records should be deleted, but were: %v", rows)
+	}
+
+	result, err = Sync(ctx, client, "appXXX", db, Options{Tables: []string{"tblDistricts"}, Full: true})
+	if err != nil || len(result.Tables) != 1 || !result.Tables[0].Full || result.Tables[0].Upserted != 1 {
+		t.Errorf("should be full sync, but was: %+v, %v", result, err)
+	}
+
+	if _, err := Sync(ctx, client, "appXXX", db, Options{Tables: []string{"Unknown"}}); !errors.Is(err, airtable.ErrTableNotFound) {
+		t.Errorf("should be ErrTableNotFound, but was: %v", err)
+	}
+}
+
+func TestSync_SchemaChanges(t *testing.T) {
+	fake, client := newFakeAirtable(t)
+	db := openDB(t)
+	ctx := context.Background()
+
+	if _, err := Sync(ctx, client, "appXXX", db, Options{}); err != nil {
+		t.Fatalf("must be no error, but was: %v", err)
+	}
+
+	// add a field with values of not modified records and rename a table
+	fake.mu.Lock()
+	apartments := fake.schema.Tables[0]
+	apartments.Fields = append(apartments.Fields, &airtable.Field{ID: "fldFloors", Name: "Floors", Type: "number"})
+	fake.records["tblApartments"][1].record.Fields["Floors"] = float64(2)
+	fake.schema.Tables[1].Name = "Areas"
+	fake.mu.Unlock()
+
+	result, err := Sync(ctx, client, "appXXX", db, Options{})
+	if err != nil {
+		t.Fatalf("must be no error, but was: %v", err)
+	}
+	expected := []*TableResult{
+		{ID: "tblApartments", Name: "Apartments", Full: true, Upserted: 2},
+		{ID: "tblDistricts", Name: "Areas", Full: true, Upserted: 1},
+	}
+	if !reflect.DeepEqual(result.Tables, expected) {
+		t.Errorf("tables with changed schema should be synced fully, expected %+v, but was %+v", expected, result.Tables)
+	}
+	rows := queryRows(t, db, `SELECT "_id", "Floors" FROM "Apartments" ORDER BY "_id"`)
+	if !reflect.DeepEqual(rows, [][]any{{"recA1", nil}, {"recA2", 2.0}}) {
+		t.Errorf("new column should be filled, but was: %v", rows)
+	}
+	rows = queryRows(t, db, `SELECT "_id" FROM "Areas"`)
+	if !reflect.DeepEqual(rows, [][]any{{"recD1"}}) {
+		t.Errorf("renamed table should be filled, but was: %v", rows)
+	}
+
+	result, err = Sync(ctx, client, "appXXX", db, Options{})
+	if err != nil || result.Tables[0].Full || result.Tables[1].Full {
+		t.Errorf("unchanged tables should be synced incrementally, but was: %+v, %v", result, err)
+	}
+}
+
+func TestSync_NameConflict(t *testing.T) {
+	fake, client := newFakeAirtable(t)
+	fake.schema.Tables = append(fake.schema.Tables, &airtable.TableSchema{ID: "tblLinks", Name: "_LINKS_Apartments_District"})
+
+	_, err := Sync(context.Background(), client, "appXXX", openDB(t), Options{})
+	if !errors.Is(err, ErrNameConflict) {
+		t.Errorf("should be ErrNameConflict, but was: %v", err)
+	}
+	if len(fake.queries) != 0 {
+		t.Errorf("records should not be read, but were: %v", fake.queries)
+	}
+}
+
+func TestColumnType(t *testing.T) {
+	for _, tt := range []struct {
+		field *airtable.Field
+		typ   string
+	}{
+		{&airtable.Field{Type: "singleLineText"}, "TEXT"},
+		{&airtable.Field{Type: "autoNumber"}, "INTEGER"},
+		{&airtable.Field{Type: "percent"}, "REAL"},
+		{&airtable.Field{Type: "dateTime"}, "TEXT"},
+		{&airtable.Field{Type: "rollup", Options: map[string]any{"result": map[string]any{"type": "checkbox"}}}, "INTEGER"},
+		{&airtable.Field{Type: "formula"}, "TEXT"},
+	} {
+		if typ := ColumnType(tt.field); typ != tt.typ {
+			t.Errorf("%s: expected %s, but was %s", tt.field.Type, tt.typ, typ)
+		}
+	}
+}
@@ -7,9 +7,21 @@ package airtable
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"net/url"
 )
 
+// maxURLLength Airtable limit of the request URL length.
+// Longer list requests are sent to the POST listRecords endpoint.
+const maxURLLength = 16000
+
+// MaxBatchSize Airtable limit of records in one create, update or delete request.
+const MaxBatchSize = 10
+
+// ErrNoFieldsToMergeOn returned by upsert when no merge fields were passed.
+var ErrNoFieldsToMergeOn = errors.New("at least one field to merge on is required")
+
 type PerformUpsert struct {
 	FieldsToMergeOn []string `json:"fieldsToMergeOn"`
 }
@@ -28,6 +40,11 @@ type Records struct {
 	// For records where no match is found, a new Airtable record will be created.
 	// https://airtable.com/developers/web/api/update-multiple-records#request-performupsert
 	PerformUpsert *PerformUpsert `json:"performUpsert,omitempty"`
+
+	// CreatedRecords and UpdatedRecords are returned by upsert requests
+	// and contain IDs of the records which were created or updated.
+	CreatedRecords []string `json:"createdRecords,omitempty"`
+	UpdatedRecords []string `json:"updatedRecords,omitempty"`
 }
 
 // Table represents table object.
@@ -46,6 +63,16 @@ func (c *Client) GetTable(dbName, tableName string) *Table {
 	}
 }
 
+// DBName returns ID of the base of the table.
+func (t *Table) DBName() string {
+	return t.dbName
+}
+
+// TableName returns name or ID the table was got by.
+func (t *Table) TableName() string {
+	return t.tableName
+}
+
 // GetRecordsWithParams get records with url values params
 // https://airtable.com/{yourDatabaseID}/api/docs#curl/table:{yourTableName}:list
 func (t *Table) GetRecordsWithParams(params url.Values) (*Records, error) {
@@ -57,7 +84,13 @@ func (t *Table) GetRecordsWithParams(params url.Values) (*Records, error) {
 func (t *Table) GetRecordsWithParamsContext(ctx context.Context, params url.Values) (*Records, error) {
 	records := new(Records)
 
-	err := t.client.get(ctx, t.dbName, t.tableName, "", params, records)
+	key := recordsCacheKey(t.dbName, t.tableName, params)
+	err := t.client.cached(ctx, t.dbName, key, t.client.cacheTTL(cacheRecords), records, func() error {
+		if len(t.client.baseURL)+len(t.dbName)+len(t.tableName)+len(params.Encode()) > maxURLLength {
+			return t.listRecords(ctx, params, records)
+		}
+		return t.client.get(ctx, t.dbName, t.tableName, "", params, records)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -70,6 +103,33 @@ func (t *Table) GetRecordsWithParamsContext(ctx context.Context, params url.Valu
 	return records, nil
 }
 
+// listRecordsContext get records with POST request
+// passing the params in JSON body.
+func (t *Table) listRecordsContext(ctx context.Context, params url.Values) (*Records, error) {
+	records := new(Records)
+
+	err := t.listRecords(ctx, params, records)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, record := range records.Records {
+		record.client = t.client
+		record.table = t
+	}
+
+	return records, nil
+}
+
+func (t *Table) listRecords(ctx context.Context, params url.Values, records *Records) error {
+	body, err := listRecordsBody(params)
+	if err != nil {
+		return err
+	}
+
+	return t.client.postRead(ctx, t.dbName, t.tableName+"/listRecords", body, records)
+}
+
 // AddRecords method to add lines to table (up to 10 in one request)
 // https://airtable.com/{yourDatabaseID}/api/docs#curl/table:{yourTableName}:create
 func (t *Table) AddRecords(records *Records) (*Records, error) {
@@ -138,6 +198,51 @@ func (t *Table) UpdateRecordsPartialContext(ctx context.Context, records *Record
 	return response, nil
 }
 
+// Upsert update records matched by fieldsToMergeOn and create the rest.
+// Records are sent in batches of 10, so any number of them can be passed.
+// https://airtable.com/developers/web/api/update-multiple-records#request-performupsert
+func (t *Table) Upsert(records *Records, fieldsToMergeOn ...string) (*Records, error) {
+	return t.UpsertContext(context.Background(), records, fieldsToMergeOn...)
+}
+
+// UpsertContext update records matched by fieldsToMergeOn and create the rest
+// with custom context.
+// If a batch fails, the records of the previous batches are returned with the error.
+func (t *Table) UpsertContext(ctx context.Context, records *Records, fieldsToMergeOn ...string) (*Records, error) {
+	if len(fieldsToMergeOn) == 0 {
+		return nil, ErrNoFieldsToMergeOn
+	}
+
+	result := new(Records)
+
+	for start := 0; start < len(records.Records); start += MaxBatchSize {
+		end := min(start+MaxBatchSize, len(records.Records))
+
+		data := &Records{
+			Records:       records.Records[start:end],
+			Typecast:      records.Typecast,
+			PerformUpsert: &PerformUpsert{FieldsToMergeOn: fieldsToMergeOn},
+		}
+		response := new(Records)
+
+		err := t.client.patch(ctx, t.dbName, t.tableName, data, response)
+		if err != nil {
+			return result, fmt.Errorf("upsert records %d-%d: %w", start, end-1, err)
+		}
+
+		for _, record := range response.Records {
+			record.client = t.client
+			record.table = t
+		}
+
+		result.Records = append(result.Records, response.Records...)
+		result.CreatedRecords = append(result.CreatedRecords, response.CreatedRecords...)
+		result.UpdatedRecords = append(result.UpdatedRecords, response.UpdatedRecords...)
+	}
+
+	return result, nil
+}
+
 // DeleteRecords delete records by recordID
 // up to 10 ids in one request.
 func (t *Table) DeleteRecords(recordIDs []string) (*Records, error) {
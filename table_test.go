@@ -7,6 +7,10 @@ package airtable
 
 import (
 	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -87,7 +91,66 @@ func TestTable_UpdateRecordsPartial(t *testing.T) {
 	}
 }
 
+func TestTable_Upsert(t *testing.T) {
+	table := testTable()
+	table.client.baseURL = mockResponse("upsert_records.json").URL
+	toSend := new(Records)
+	for i := 0; i < 15; i++ {
+		toSend.Records = append(toSend.Records, &Record{Fields: map[string]any{"Field1": "Field1"}})
+	}
+	records, err := table.Upsert(toSend, "Field1")
+	if err != nil {
+		t.Errorf("must be no error, but was: %v", err)
+	}
+	if len(records.Records) != 4 {
+		t.Errorf("should be 4 records from two batches in result, but was: %v", len(records.Records))
+	}
+	if len(records.CreatedRecords) != 2 || records.CreatedRecords[0] != "recr3qAQbM7juKa4o" {
+		t.Errorf("unexpected created records: %v", records.CreatedRecords)
+	}
+	if len(records.UpdatedRecords) != 2 || records.UpdatedRecords[0] != "recnTq6CsvFM6vX2m" {
+		t.Errorf("unexpected updated records: %v", records.UpdatedRecords)
+	}
+	_, err = table.Upsert(toSend)
+	if !errors.Is(err, ErrNoFieldsToMergeOn) {
+		t.Errorf("should be ErrNoFieldsToMergeOn, but was: %v", err)
+	}
+	table.client.baseURL = mockErrorResponse(422).URL
+	_, err = table.Upsert(toSend, "Field1")
+	var e *HTTPClientError
+	if !errors.As(err, &e) {
+		t.Errorf("should be an http error, but was not: %v", err)
+	}
+
+	upserted, err := os.ReadFile(filepath.Join("testdata", "upsert_records.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	batches := 0
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		batches++
+		if batches > 1 {
+			http.Error(rw, "An error occurred", http.StatusUnprocessableEntity)
+			return
+		}
+		_, _ = rw.Write(upserted)
+	}))
+	defer server.Close()
+	table.client.baseURL = server.URL
+	records, err = table.Upsert(toSend, "Field1")
+	if !errors.As(err, &e) || records == nil || len(records.Records) != 2 {
+		t.Errorf("records of the first batch should be returned with the error, but was: %v, %v", records, err)
+	}
+}
+
 func testTable() *Table {
 	client := testClient()
 	return client.GetTable("dbName", "tableName")
 }
+
+func TestTable_Names(t *testing.T) {
+	table := testTable()
+	if table.DBName() != "dbName" || table.TableName() != "tableName" {
+		t.Errorf("unexpected names: %s, %s", table.DBName(), table.TableName())
+	}
+}
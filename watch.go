@@ -0,0 +1,229 @@
+// Copyright © 2020 Mike Berezin
+//
+// Use of this source code is governed by an MIT license.
+// Details in the LICENSE file.
+
+package airtable
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"iter"
+	"maps"
+	"slices"
+	"time"
+)
+
+// watchClockSkew is subtracted from the previous poll time in the filter,
+// records read again without changes are not reported.
+const watchClockSkew = time.Minute
+
+// ErrWatchInterval returned by Watch for not positive interval.
+var ErrWatchInterval = errors.New("watch interval must be positive")
+
+// WatchEventType type of the record change.
+type WatchEventType string
+
+// Types of the record changes.
+const (
+	RecordCreated WatchEventType = "created"
+	RecordUpdated WatchEventType = "updated"
+	RecordDeleted WatchEventType = "deleted"
+)
+
+// WatchEvent change of the record found by Watch.
+type WatchEvent struct {
+	Type WatchEventType
+	// Record changed, deleted record has only ID.
+	Record *Record
+}
+
+// WatchOptions options of the table watch.
+type WatchOptions struct {
+	// Fields to return, all fields if empty.
+	// Changes of other fields are not reported.
+	Fields []string
+	// View to watch, records leaving the view are reported as deleted.
+	View string
+	// Initial reports records existing at the start as created.
+	Initial bool
+	// SkipDeleted disables listing of all record IDs every poll to find deleted records.
+	SkipDeleted bool
+	// IDField is the only field read by the listing of record IDs, the first of Fields
+	// by default or the primary field of the table read from the base schema.
+	IDField string
+}
+
+// Watch polls the table every interval and yields created, updated and deleted records.
+// The first poll reads all records, next ones read records created or modified
+// since the previous poll with LAST_MODIFIED_TIME() filter
+// and yield the records which fields differ from the known ones.
+// The next poll starts an interval after the events of the previous one are consumed,
+// so a slow consumer slows down polling. Poll errors are yielded
+// and the poll is repeated after the interval if the iteration continues.
+// Watch stops when ctx is done or the iteration is stopped.
+// Not positive interval yields ErrWatchInterval.
+func (t *Table) Watch(ctx context.Context, interval time.Duration, opts *WatchOptions) iter.Seq2[*WatchEvent, error] {
+	if opts == nil {
+		opts = &WatchOptions{}
+	}
+	return func(yield func(*WatchEvent, error) bool) {
+		if interval <= 0 {
+			yield(nil, ErrWatchInterval)
+			return
+		}
+		w := &watcher{table: t, opts: opts}
+		timer := time.NewTimer(0)
+		defer timer.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-timer.C:
+			}
+			events, err := w.poll(ctx)
+			if err != nil {
+				if ctx.Err() != nil || !yield(nil, err) {
+					return
+				}
+			}
+			for _, event := range events {
+				if !yield(event, nil) {
+					return
+				}
+			}
+			timer.Reset(interval)
+		}
+	}
+}
+
+// watcher state of the watch between polls.
+type watcher struct {
+	table *Table
+	opts  *WatchOptions
+	// known fingerprints of fields by record IDs, nil before the first poll.
+	known map[string]uint64
+	// polled start time of the last successful poll.
+	polled time.Time
+	// primaryFieldID of the table, read once for the listing of record IDs.
+	primaryFieldID string
+}
+
+func (w *watcher) records(fields ...string) *GetRecordsConfig {
+	grc := w.table.GetRecords().ReturnFields(fields...)
+	if w.opts.View != "" {
+		grc.FromView(w.opts.View)
+	}
+	return grc
+}
+
+// poll returns changes since the previous poll,
+// state is changed only if the poll succeeds.
+// Cached responses are not used, they would hide the changes.
+func (w *watcher) poll(ctx context.Context) ([]*WatchEvent, error) {
+	ctx = withoutCache(ctx)
+	start := time.Now()
+	first := w.known == nil
+
+	grc := w.records(w.opts.Fields...)
+	if !first {
+		grc.WithFilterFormula(ModifiedSince(w.polled.Add(-watchClockSkew)))
+	}
+	var events []*WatchEvent
+	changed := map[string]uint64{}
+	for record, err := range grc.Iterate(ctx) {
+		if err != nil {
+			return nil, err
+		}
+		fingerprint, err := fieldsFingerprint(record.Fields)
+		if err != nil {
+			return nil, err
+		}
+		known, ok := w.known[record.ID]
+		switch {
+		case first && !w.opts.Initial:
+		case !ok:
+			events = append(events, &WatchEvent{Type: RecordCreated, Record: record})
+		case known != fingerprint:
+			events = append(events, &WatchEvent{Type: RecordUpdated, Record: record})
+		}
+		changed[record.ID] = fingerprint
+	}
+
+	var ids map[string]bool
+	if !first && !w.opts.SkipDeleted {
+		idField, err := w.idField(ctx)
+		if err != nil {
+			return nil, err
+		}
+		grc := w.records(idField)
+		ids = map[string]bool{}
+		for record, err := range grc.Iterate(ctx) {
+			if err != nil {
+				return nil, err
+			}
+			ids[record.ID] = true
+		}
+	}
+
+	if first {
+		w.known = map[string]uint64{}
+	}
+	for id, fingerprint := range changed {
+		w.known[id] = fingerprint
+	}
+	if ids != nil {
+		for _, id := range slices.Sorted(maps.Keys(w.known)) {
+			if !ids[id] {
+				events = append(events, &WatchEvent{Type: RecordDeleted, Record: &Record{ID: id}})
+				delete(w.known, id)
+			}
+		}
+	}
+	w.polled = start
+	return events, nil
+}
+
+// idField returns the field read by the listing of record IDs.
+func (w *watcher) idField(ctx context.Context) (string, error) {
+	switch {
+	case w.opts.IDField != "":
+		return w.opts.IDField, nil
+	case len(w.opts.Fields) > 0:
+		return w.opts.Fields[0], nil
+	}
+	if w.primaryFieldID == "" {
+		schema, err := w.table.GetSchemaContext(ctx)
+		if err != nil {
+			return "", err
+		}
+		w.primaryFieldID = schema.PrimaryFieldID
+	}
+	return w.primaryFieldID, nil
+}
+
+// ModifiedSince returns filter formula matching records created or modified after t.
+// Records modified in the same second as t may not match,
+// subtract a margin from t to read them.
+func ModifiedSince(t time.Time) string {
+	timestamp := t.UTC().Format(time.RFC3339)
+	return fmt.Sprintf(
+		"OR(IS_AFTER(CREATED_TIME(), DATETIME_PARSE('%[1]s')), IS_AFTER(LAST_MODIFIED_TIME(), DATETIME_PARSE('%[1]s')))",
+		timestamp,
+	)
+}
+
+// fieldsFingerprint hashes fields, JSON object keys are sorted
+// so equal fields have equal hashes.
+func fieldsFingerprint(fields map[string]any) (uint64, error) {
+	b, err := json.Marshal(fields)
+	if err != nil {
+		return 0, err
+	}
+	h := fnv.New64a()
+	_, _ = h.Write(b)
+	return h.Sum64(), nil
+}
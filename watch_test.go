@@ -0,0 +1,186 @@
+// Copyright © 2020 Mike Berezin
+//
+// Use of this source code is governed by an MIT license.
+// Details in the LICENSE file.
+
+package airtable
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// watchServer serves schema and records of the table, requests with filter formula
+// get only modified records.
+type watchServer struct {
+	mu       sync.Mutex
+	records  []*Record
+	modified []string
+	fail     int
+	queries  []url.Values
+}
+
+func (s *watchServer) ServeHTTP(rw http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if strings.HasPrefix(r.URL.Path, "/meta/") {
+		_, _ = rw.Write([]byte(`{"tables": [{"id": "tblX", "name": "tableName", "primaryFieldId": "fldName"}]}`))
+		return
+	}
+	s.queries = append(s.queries, r.URL.Query())
+	if s.fail > 0 {
+		s.fail--
+		http.Error(rw, "An error occurred", http.StatusInternalServerError)
+		return
+	}
+	records := s.records
+	if r.URL.Query().Get("filterByFormula") != "" {
+		records = nil
+		for _, record := range s.records {
+			if slices.Contains(s.modified, record.ID) {
+				records = append(records, record)
+			}
+		}
+	}
+	_ = json.NewEncoder(rw).Encode(&Records{Records: records})
+}
+
+func TestTable_Watch(t *testing.T) {
+	ws := &watchServer{records: []*Record{
+		{ID: "recA", Fields: map[string]any{"Name": "A"}},
+		{ID: "recB", Fields: map[string]any{"Name": "B"}},
+		{ID: "recE", Fields: map[string]any{"Name": "E"}},
+	}}
+	server := httptest.NewServer(ws)
+	defer server.Close()
+	table := testTable()
+	table.client.baseURL = server.URL
+
+	var events []string
+	var errs []error
+	opts := &WatchOptions{Fields: []string{"Name", "Rooms"}, Initial: true}
+	for event, err := range table.Watch(context.Background(), time.Millisecond, opts) {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		events = append(events, string(event.Type)+" "+event.Record.ID)
+		if len(events) == 3 {
+			ws.mu.Lock()
+			ws.records = []*Record{
+				{ID: "recA", Fields: map[string]any{"Name": "A2"}},
+				{ID: "recC", Fields: map[string]any{"Name": "C"}},
+				{ID: "recE", Fields: map[string]any{"Name": "E"}},
+			}
+			ws.modified = []string{"recA", "recC", "recE"}
+			ws.fail = 1
+			ws.mu.Unlock()
+		}
+		if len(events) == 6 {
+			break
+		}
+	}
+
+	expected := []string{"created recA", "created recB", "created recE", "updated recA", "created recC", "deleted recB"}
+	if !reflect.DeepEqual(events, expected) {
+		t.Errorf("expected %q, but was %q", expected, events)
+	}
+	var httpErr *HTTPClientError
+	if len(errs) != 1 || !errors.As(errs[0], &httpErr) {
+		t.Errorf("poll error should be yielded once, but was: %v", errs)
+	}
+
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	if len(ws.queries) != 4 {
+		t.Fatalf("expected 4 requests, but was: %v", ws.queries)
+	}
+	if formula := ws.queries[2].Get("filterByFormula"); !strings.Contains(formula, "LAST_MODIFIED_TIME()") {
+		t.Errorf("should filter modified records, but was: %q", formula)
+	}
+	if fields := ws.queries[2]["fields[]"]; !reflect.DeepEqual(fields, opts.Fields) {
+		t.Errorf("should return fields %q, but was: %q", opts.Fields, fields)
+	}
+	if fields := ws.queries[3]["fields[]"]; !reflect.DeepEqual(fields, []string{"Name"}) || ws.queries[3].Has("filterByFormula") {
+		t.Errorf("should list IDs of all records, but was: %v", ws.queries[3])
+	}
+}
+
+func TestTable_WatchCancel(t *testing.T) {
+	ws := &watchServer{records: []*Record{{ID: "recA", Fields: map[string]any{}}}}
+	server := httptest.NewServer(ws)
+	defer server.Close()
+	table := testTable()
+	table.client.baseURL = server.URL
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for event, err := range table.Watch(ctx, time.Millisecond, nil) {
+			t.Errorf("no events are expected, but was: %v, %v", event, err)
+		}
+	}()
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("watch should stop when context is canceled")
+	}
+}
+
+func TestTable_WatchPrimaryField(t *testing.T) {
+	ws := &watchServer{records: []*Record{{ID: "recA", Fields: map[string]any{"Name": "A"}}}}
+	server := httptest.NewServer(ws)
+	defer server.Close()
+	table := testTable()
+	table.client.baseURL = server.URL
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(20*time.Millisecond, cancel)
+	for event, err := range table.Watch(ctx, time.Millisecond, nil) {
+		t.Errorf("no events are expected, but was: %v, %v", event, err)
+	}
+
+	ws.mu.Lock()
+	defer ws.mu.Unlock()
+	if len(ws.queries) < 3 {
+		t.Fatalf("expected at least 3 requests, but was: %v", ws.queries)
+	}
+	if fields := ws.queries[2]["fields[]"]; !reflect.DeepEqual(fields, []string{"fldName"}) {
+		t.Errorf("IDs should be listed with the primary field, but was: %q", fields)
+	}
+}
+
+func TestModifiedSince(t *testing.T) {
+	formula := ModifiedSince(time.Date(2024, 5, 1, 12, 0, 0, 0, time.FixedZone("", 3600)))
+	expected := "OR(IS_AFTER(CREATED_TIME(), DATETIME_PARSE('2024-05-01T11:00:00Z')), IS_AFTER(LAST_MODIFIED_TIME(), DATETIME_PARSE('2024-05-01T11:00:00Z')))"
+	if formula != expected {
+		t.Errorf("expected %s, but was %s", expected, formula)
+	}
+}
+
+func TestTable_WatchInterval(t *testing.T) {
+	table := testTable()
+	var errs []error
+	for event, err := range table.Watch(context.Background(), 0, nil) {
+		if event != nil {
+			t.Errorf("no events are expected, but was: %v", event)
+		}
+		errs = append(errs, err)
+	}
+	if len(errs) != 1 || !errors.Is(errs[0], ErrWatchInterval) {
+		t.Errorf("should be ErrWatchInterval, but was: %v", errs)
+	}
+}
@@ -0,0 +1,157 @@
+// Copyright © 2020 Mike Berezin
+//
+// Use of this source code is governed by an MIT license.
+// Details in the LICENSE file.
+
+package writequeue
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// entry line of the journal, only one of the fields is set.
+type entry struct {
+	// Table names of the journal, the first line.
+	Table *journalTable `json:"table,omitempty"`
+	// Op queued or coalesced operation, replaces the operation with the same Seq.
+	Op *Op `json:"op,omitempty"`
+	// Done Seq of the sent operation.
+	Done uint64 `json:"done,omitempty"`
+	// Failed operation rejected by the API.
+	Failed *Failure `json:"failed,omitempty"`
+}
+
+type journalTable struct {
+	Base  string `json:"base"`
+	Table string `json:"table"`
+}
+
+// journal append-only JSON Lines file of the queue entries.
+type journal struct {
+	file *os.File
+}
+
+// readJournal reads pending operations and failures from the file.
+// A torn last line left by an interrupted write is ignored,
+// other undecodable lines are errors.
+func readJournal(path string) (*journalTable, []*Op, []*Failure, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	defer f.Close()
+
+	var table *journalTable
+	var ops []*Op
+	var failures []*Failure
+	index := map[uint64]int{}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 16<<20)
+	var torn error
+	for line := 1; scanner.Scan(); line++ {
+		if torn != nil {
+			return nil, nil, nil, fmt.Errorf("corrupted journal line %d: %w", line-1, torn)
+		}
+		e := new(entry)
+		if err := json.Unmarshal(scanner.Bytes(), e); err != nil {
+			// may be a torn write of the last entry
+			torn = err
+			continue
+		}
+		switch {
+		case e.Table != nil:
+			table = e.Table
+		case e.Op != nil:
+			if i, ok := index[e.Op.Seq]; ok {
+				ops[i] = e.Op
+			} else {
+				index[e.Op.Seq] = len(ops)
+				ops = append(ops, e.Op)
+			}
+		case e.Done != 0:
+			if i, ok := index[e.Done]; ok {
+				ops[i] = nil
+			}
+		case e.Failed != nil:
+			if i, ok := index[e.Failed.Op.Seq]; ok {
+				ops[i] = nil
+			}
+			failures = append(failures, e.Failed)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, nil, fmt.Errorf("cannot read journal: %w", err)
+	}
+
+	pending := ops[:0]
+	for _, op := range ops {
+		if op != nil {
+			pending = append(pending, op)
+		}
+	}
+	return table, pending, failures, nil
+}
+
+// writeJournal atomically replaces the file with the entries
+// and opens it for appending.
+func writeJournal(path string, entries []*entry) (*journal, error) {
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return nil, err
+	}
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for _, e := range entries {
+		if err := enc.Encode(e); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+	if err := w.Flush(); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return nil, err
+	}
+	if err := f.Close(); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return nil, err
+	}
+
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	return &journal{file: file}, nil
+}
+
+// append writes the entry and syncs the file.
+func (j *journal) append(e *entry) error {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if _, err := j.file.Write(append(b, '\n')); err != nil {
+		return fmt.Errorf("cannot write journal: %w", err)
+	}
+	if err := j.file.Sync(); err != nil {
+		return fmt.Errorf("cannot write journal: %w", err)
+	}
+	return nil
+}
+
+func (j *journal) close() error {
+	return j.file.Close()
+}
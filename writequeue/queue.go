@@ -0,0 +1,471 @@
+// Copyright © 2020 Mike Berezin
+//
+// Use of this source code is governed by an MIT license.
+// Details in the LICENSE file.
+
+// Package writequeue queues writes to a table in a durable journal
+// and sends them when the API is available.
+//
+// Every queued operation is appended to the journal file and synced
+// before the call returns, so it survives restarts of the process.
+// Operations are sent in the queue order, consecutive operations of the same type
+// are batched by 10. Updates of the same record waiting in the queue are coalesced
+// into one, a delete drops waiting updates of the record.
+//
+// Operations failed with network errors, rate limiting or server errors
+// stay in the queue and are retried, operations rejected by the API
+// with other errors are moved to Failures. A batch rejected for invalid records
+// is sent again operation by operation, so only the invalid ones fail.
+//
+// Delivery is at least once. Airtable has no idempotency keys, so an add sent
+// before a crash but not yet marked done in the journal is sent again after Open
+// and creates a duplicate record. Updates and deletes are safe to repeat.
+// Write a unique key to a field of added records and upsert by it
+// with airtable.Table.Upsert if duplicates are not acceptable.
+package writequeue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/mehanizm/airtable"
+)
+
+// ErrJournalMismatch returned when the journal belongs to another table.
+var ErrJournalMismatch = errors.New("journal was written for other table")
+
+// OpType type of the queued operation.
+type OpType string
+
+// Types of the operations.
+const (
+	OpAdd    OpType = "add"
+	OpUpdate OpType = "update"
+	OpDelete OpType = "delete"
+)
+
+// Op queued operation.
+type Op struct {
+	// Seq number of the operation in the queue, starts from 1.
+	Seq  uint64 `json:"seq"`
+	Type OpType `json:"type"`
+	// RecordID of updated or deleted record.
+	RecordID string `json:"recordId,omitempty"`
+	// Fields of added record or changed fields of updated record.
+	Fields map[string]any `json:"fields,omitempty"`
+	Time   time.Time      `json:"time"`
+}
+
+// Failure operation rejected by the API.
+type Failure struct {
+	Op    *Op       `json:"op"`
+	Error string    `json:"error"`
+	Time  time.Time `json:"time"`
+}
+
+// Options of the queue.
+type Options struct {
+	// Typecast sends records with typecast.
+	Typecast bool
+	// RetryInterval is the delay of Run after a failed flush, a minute by default.
+	RetryInterval time.Duration
+}
+
+// Queue durable queue of writes to the table, safe for concurrent use.
+type Queue struct {
+	table *airtable.Table
+	path  string
+	opts  Options
+
+	mu       sync.Mutex
+	journal  *journal
+	ops      []*Op
+	failures []*Failure
+	seq      uint64
+	// inFlight Seq of operations being sent, they are not coalesced.
+	inFlight map[uint64]bool
+	// flushing serializes flushes.
+	flushing sync.Mutex
+	notify   chan struct{}
+}
+
+// Open opens the queue of the table with the journal at path,
+// operations left in the journal are queued again.
+func Open(table *airtable.Table, path string, opts Options) (*Queue, error) {
+	if opts.RetryInterval == 0 {
+		opts.RetryInterval = time.Minute
+	}
+	journalTable, ops, failures, err := readJournal(path)
+	if err != nil {
+		return nil, err
+	}
+	if journalTable != nil && (journalTable.Base != table.DBName() || journalTable.Table != table.TableName()) {
+		return nil, fmt.Errorf("%w: %s/%s", ErrJournalMismatch, journalTable.Base, journalTable.Table)
+	}
+
+	q := &Queue{
+		table:    table,
+		path:     path,
+		opts:     opts,
+		ops:      ops,
+		failures: failures,
+		inFlight: map[uint64]bool{},
+		notify:   make(chan struct{}, 1),
+	}
+	for _, op := range ops {
+		q.seq = max(q.seq, op.Seq)
+	}
+	for _, failure := range failures {
+		q.seq = max(q.seq, failure.Op.Seq)
+	}
+	if err := q.compact(); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// compact rewrites the journal with pending operations and failures.
+func (q *Queue) compact() error {
+	entries := []*entry{{Table: &journalTable{Base: q.table.DBName(), Table: q.table.TableName()}}}
+	for _, failure := range q.failures {
+		entries = append(entries, &entry{Failed: failure})
+	}
+	for _, op := range q.ops {
+		entries = append(entries, &entry{Op: op})
+	}
+	if q.journal != nil {
+		if err := q.journal.close(); err != nil {
+			return err
+		}
+		q.journal = nil
+	}
+	j, err := writeJournal(q.path, entries)
+	if err != nil {
+		return fmt.Errorf("cannot write journal: %w", err)
+	}
+	q.journal = j
+	return nil
+}
+
+// Add queues creation of the record.
+// The record may be created twice if the process stops while it is sent.
+func (q *Queue) Add(fields map[string]any) error {
+	return q.enqueue(&Op{Type: OpAdd, Fields: maps.Clone(fields)})
+}
+
+// Update queues partial update of the record.
+// Fields are merged into the update of the record waiting in the queue if there is one.
+func (q *Queue) Update(recordID string, fields map[string]any) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if op := q.lastOp(recordID); op != nil && op.Type == OpUpdate && !q.inFlight[op.Seq] {
+		merged := *op
+		merged.Fields = maps.Clone(op.Fields)
+		maps.Copy(merged.Fields, fields)
+		if err := q.journal.append(&entry{Op: &merged}); err != nil {
+			return err
+		}
+		*op = merged
+		q.signal()
+		return nil
+	}
+	return q.enqueueLocked(&Op{Type: OpUpdate, RecordID: recordID, Fields: maps.Clone(fields)})
+}
+
+// Delete queues deletion of the record
+// and drops the updates of the record waiting in the queue.
+func (q *Queue) Delete(recordID string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	// the queue is changed only after all entries are written,
+	// so it matches the journal if a write fails
+	var ops []*Op
+	for _, op := range q.ops {
+		if op.RecordID == recordID && op.Type == OpUpdate && !q.inFlight[op.Seq] {
+			if err := q.journal.append(&entry{Done: op.Seq}); err != nil {
+				return err
+			}
+			continue
+		}
+		ops = append(ops, op)
+	}
+	q.ops = ops
+	return q.enqueueLocked(&Op{Type: OpDelete, RecordID: recordID})
+}
+
+func (q *Queue) enqueue(op *Op) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.enqueueLocked(op)
+}
+
+func (q *Queue) enqueueLocked(op *Op) error {
+	op.Seq = q.seq + 1
+	op.Time = time.Now().UTC()
+	if err := q.journal.append(&entry{Op: op}); err != nil {
+		return err
+	}
+	q.seq = op.Seq
+	q.ops = append(q.ops, op)
+	q.signal()
+	return nil
+}
+
+// lastOp returns the last queued operation of the record.
+func (q *Queue) lastOp(recordID string) *Op {
+	for i := len(q.ops) - 1; i >= 0; i-- {
+		if q.ops[i].RecordID == recordID {
+			return q.ops[i]
+		}
+	}
+	return nil
+}
+
+// signal wakes up Run.
+func (q *Queue) signal() {
+	select {
+	case q.notify <- struct{}{}:
+	default:
+	}
+}
+
+// Depth returns number of operations waiting in the queue.
+func (q *Queue) Depth() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.ops)
+}
+
+// Pending returns copies of operations waiting in the queue.
+func (q *Queue) Pending() []Op {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	ops := make([]Op, len(q.ops))
+	for i, op := range q.ops {
+		ops[i] = *op
+	}
+	return ops
+}
+
+// Failures returns operations rejected by the API.
+func (q *Queue) Failures() []Failure {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	failures := make([]Failure, len(q.failures))
+	for i, failure := range q.failures {
+		failures[i] = *failure
+	}
+	return failures
+}
+
+// ClearFailures forgets rejected operations.
+func (q *Queue) ClearFailures() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.failures = nil
+	return q.compact()
+}
+
+// Flush sends queued operations in order until the queue is empty
+// or an operation fails with a temporary error, which is returned.
+// Returns number of sent operations.
+func (q *Queue) Flush(ctx context.Context) (int, error) {
+	q.flushing.Lock()
+	defer q.flushing.Unlock()
+
+	sent := 0
+	for {
+		batch := q.nextBatch()
+		if len(batch) == 0 {
+			break
+		}
+		err := q.send(ctx, batch)
+		if rejected(err) && len(batch) > 1 {
+			n, err := q.sendEach(ctx, batch)
+			sent += n
+			if err != nil {
+				return sent, err
+			}
+			continue
+		}
+		if err != nil && Temporary(err) {
+			q.release(batch)
+			return sent, err
+		}
+		if err := q.complete(batch, err); err != nil {
+			return sent, err
+		}
+		if err == nil {
+			sent += len(batch)
+		}
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.ops) == 0 {
+		// journal is truncated when the queue is drained
+		if err := q.compact(); err != nil {
+			return sent, err
+		}
+	}
+	return sent, nil
+}
+
+// nextBatch marks in flight up to airtable.MaxBatchSize operations of the same type
+// from the head of the queue, records are not repeated in a batch.
+func (q *Queue) nextBatch() []*Op {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	var batch []*Op
+	records := map[string]bool{}
+	for _, op := range q.ops {
+		if len(batch) == airtable.MaxBatchSize || len(batch) > 0 && op.Type != batch[0].Type || op.RecordID != "" && records[op.RecordID] {
+			break
+		}
+		records[op.RecordID] = true
+		batch = append(batch, op)
+		q.inFlight[op.Seq] = true
+	}
+	return batch
+}
+
+func (q *Queue) send(ctx context.Context, batch []*Op) error {
+	switch batch[0].Type {
+	case OpDelete:
+		ids := make([]string, len(batch))
+		for i, op := range batch {
+			ids[i] = op.RecordID
+		}
+		_, err := q.table.DeleteRecordsContext(ctx, ids)
+		return err
+	}
+
+	records := &airtable.Records{Typecast: q.opts.Typecast}
+	for _, op := range batch {
+		records.Records = append(records.Records, &airtable.Record{ID: op.RecordID, Fields: op.Fields})
+	}
+	var err error
+	if batch[0].Type == OpAdd {
+		_, err = q.table.AddRecordsContext(ctx, records)
+	} else {
+		_, err = q.table.UpdateRecordsPartialContext(ctx, records)
+	}
+	return err
+}
+
+// sendEach sends operations of the rejected batch one by one.
+// Returns number of sent operations and a temporary or journal error.
+func (q *Queue) sendEach(ctx context.Context, batch []*Op) (int, error) {
+	sent := 0
+	for i, op := range batch {
+		err := q.send(ctx, []*Op{op})
+		if err != nil && Temporary(err) {
+			q.release(batch[i:])
+			return sent, err
+		}
+		if err := q.complete([]*Op{op}, err); err != nil {
+			return sent, err
+		}
+		if err == nil {
+			sent++
+		}
+	}
+	return sent, nil
+}
+
+// release returns the batch to the queue after a temporary error.
+func (q *Queue) release(batch []*Op) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, op := range batch {
+		delete(q.inFlight, op.Seq)
+	}
+}
+
+// complete removes the batch from the queue, to failures if sendErr is not nil.
+func (q *Queue) complete(batch []*Op, sendErr error) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, op := range batch {
+		e := &entry{Done: op.Seq}
+		if sendErr != nil {
+			e = &entry{Failed: &Failure{Op: op, Error: sendErr.Error(), Time: time.Now().UTC()}}
+		}
+		if err := q.journal.append(e); err != nil {
+			return err
+		}
+		if e.Failed != nil {
+			q.failures = append(q.failures, e.Failed)
+		}
+		delete(q.inFlight, op.Seq)
+	}
+	q.ops = q.ops[len(batch):]
+	return nil
+}
+
+// Run flushes the queue when operations are queued until ctx is done,
+// after a temporary error the flush is retried in RetryInterval.
+// Errors are reported to onError if it is not nil.
+func (q *Queue) Run(ctx context.Context, onError func(error)) {
+	q.signal()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-q.notify:
+		}
+		_, err := q.Flush(ctx)
+		if err == nil || ctx.Err() != nil {
+			continue
+		}
+		if onError != nil {
+			onError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(q.opts.RetryInterval):
+			q.signal()
+		}
+	}
+}
+
+// Close closes the journal, queued operations are kept in it.
+func (q *Queue) Close() error {
+	q.flushing.Lock()
+	defer q.flushing.Unlock()
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.journal.close()
+}
+
+// rejected reports whether the API rejected the request for invalid records,
+// nothing is written then.
+func rejected(err error) bool {
+	var httpErr *airtable.HTTPClientError
+	return errors.As(err, &httpErr) && (httpErr.StatusCode == http.StatusBadRequest ||
+		httpErr.StatusCode == http.StatusNotFound || httpErr.StatusCode == http.StatusUnprocessableEntity)
+}
+
+// Temporary reports whether the write failed with a network error,
+// rate limiting, a server error or cancellation and can be retried.
+// Other errors, such as undecodable responses, are not temporary.
+func Temporary(err error) bool {
+	var httpErr *airtable.HTTPClientError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode == http.StatusTooManyRequests || httpErr.StatusCode >= http.StatusInternalServerError
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, context.Canceled) ||
+		errors.Is(err, context.DeadlineExceeded)
+}
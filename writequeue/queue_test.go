@@ -0,0 +1,329 @@
+// Copyright © 2020 Mike Berezin
+//
+// Use of this source code is governed by an MIT license.
+// Details in the LICENSE file.
+
+package writequeue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mehanizm/airtable"
+	"github.com/mehanizm/airtable/internal/airtabletest"
+)
+
+// fakeAirtable records requests and responds with the status
+// set for the method, 200 by default, or with 422 to requests containing reject if it is set.
+type fakeAirtable struct {
+	mu       sync.Mutex
+	status   map[string]int
+	reject   string
+	requests []string
+}
+
+func (f *fakeAirtable) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	body, _ := io.ReadAll(r.Body)
+	if status := f.status[r.Method]; status != 0 {
+		http.Error(w, `{"error": {"type": "FAILED"}}`, status)
+		return
+	}
+	if f.reject != "" && strings.Contains(string(body), f.reject) {
+		http.Error(w, `{"error": {"type": "INVALID_VALUE_FOR_COLUMN"}}`, http.StatusUnprocessableEntity)
+		return
+	}
+	query, _ := url.QueryUnescape(r.URL.RawQuery)
+	f.requests = append(f.requests, r.Method+" "+query+string(body))
+	_, _ = w.Write([]byte(`{"records": []}`))
+}
+
+func (f *fakeAirtable) setStatus(method string, status int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.status[method] = status
+}
+
+func newTable(t *testing.T) (*fakeAirtable, *airtable.Table) {
+	fake := &fakeAirtable{status: map[string]int{}}
+	client, _ := airtabletest.NewClient(t, fake)
+	return fake, client.GetTable("appXXX", "Apartments")
+}
+
+func opTypes(ops []Op) []string {
+	var types []string
+	for _, op := range ops {
+		types = append(types, string(op.Type)+" "+op.RecordID)
+	}
+	return types
+}
+
+func TestQueue(t *testing.T) {
+	fake, table := newTable(t)
+	path := filepath.Join(t.TempDir(), "queue.journal")
+	ctx := context.Background()
+
+	q, err := Open(table, path, Options{Typecast: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, write := range []func() error{
+		func() error { return q.Add(map[string]any{"Name": "Loft"}) },
+		func() error { return q.Update("recA", map[string]any{"Name": "Studio"}) },
+		func() error { return q.Update("recA", map[string]any{"Rooms": 2}) },
+		func() error { return q.Add(map[string]any{"Name": "House"}) },
+		func() error { return q.Update("recB", map[string]any{"Name": "Flat"}) },
+		func() error { return q.Delete("recB") },
+		func() error { return q.Update("recC", map[string]any{"Name": "Cottage"}) },
+	} {
+		if err := write(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expected := []string{"add ", "update recA", "add ", "delete recB", "update recC"}
+	if types := opTypes(q.Pending()); !reflect.DeepEqual(types, expected) {
+		t.Errorf("expected %q, but was %q", expected, types)
+	}
+
+	fake.setStatus(http.MethodPost, http.StatusServiceUnavailable)
+	sent, err := q.Flush(ctx)
+	if !Temporary(err) || sent != 0 || q.Depth() != 5 {
+		t.Errorf("operations should stay in the queue, but was: %d, %d, %v", sent, q.Depth(), err)
+	}
+	if err := q.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	q, err = Open(table, path, Options{Typecast: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if types := opTypes(q.Pending()); !reflect.DeepEqual(types, expected) {
+		t.Errorf("operations should be read from the journal, but were %q", types)
+	}
+	if fields := q.Pending()[1].Fields; !reflect.DeepEqual(fields, map[string]any{"Name": "Studio", "Rooms": float64(2)}) {
+		t.Errorf("updates should be coalesced, but was: %v", fields)
+	}
+
+	fake.setStatus(http.MethodPost, 0)
+	fake.setStatus(http.MethodPatch, http.StatusUnprocessableEntity)
+	sent, err = q.Flush(ctx)
+	if err != nil || sent != 3 || q.Depth() != 0 {
+		t.Errorf("queue should be flushed, but was: %d, %d, %v", sent, q.Depth(), err)
+	}
+	expectedRequests := []string{
+		`POST {"records":[{"fields":{"Name":"Loft"}}],"typecast":true}`,
+		`POST {"records":[{"fields":{"Name":"House"}}],"typecast":true}`,
+		`DELETE records[]=recB`,
+	}
+	if !reflect.DeepEqual(fake.requests, expectedRequests) {
+		t.Errorf("expected requests %q, but were %q", expectedRequests, fake.requests)
+	}
+	failures := q.Failures()
+	if len(failures) != 2 || failures[0].Op.RecordID != "recA" || failures[1].Op.RecordID != "recC" {
+		t.Errorf("rejected updates should be failures, but were: %+v", failures)
+	}
+
+	q.Close()
+	q, err = Open(table, path, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer q.Close()
+	if q.Depth() != 0 || len(q.Failures()) != 2 {
+		t.Errorf("failures should be read from the journal, but was: %d, %+v", q.Depth(), q.Failures())
+	}
+	if err := q.ClearFailures(); err != nil || len(q.Failures()) != 0 {
+		t.Errorf("failures should be cleared, but was: %+v, %v", q.Failures(), err)
+	}
+}
+
+func TestQueue_Batch(t *testing.T) {
+	fake, table := newTable(t)
+	q, err := Open(table, filepath.Join(t.TempDir(), "queue.journal"), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer q.Close()
+	for i := 0; i < 12; i++ {
+		if err := q.Delete("rec" + string(rune('A'+i))); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if sent, err := q.Flush(context.Background()); err != nil || sent != 12 {
+		t.Fatalf("queue should be flushed, but was: %d, %v", sent, err)
+	}
+	if len(fake.requests) != 2 {
+		t.Errorf("deletes should be sent in batches of 10, but were: %q", fake.requests)
+	}
+}
+
+func TestQueue_RejectedBatch(t *testing.T) {
+	fake, table := newTable(t)
+	fake.reject = "Invalid"
+	q, err := Open(table, filepath.Join(t.TempDir(), "queue.journal"), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer q.Close()
+	for _, name := range []string{"Loft", "Invalid", "House"} {
+		if err := q.Add(map[string]any{"Name": name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sent, err := q.Flush(context.Background())
+	if err != nil || sent != 2 || q.Depth() != 0 {
+		t.Errorf("valid operations should be sent, but was: %d, %d, %v", sent, q.Depth(), err)
+	}
+	expectedRequests := []string{
+		`POST {"records":[{"fields":{"Name":"Loft"}}]}`,
+		`POST {"records":[{"fields":{"Name":"House"}}]}`,
+	}
+	if !reflect.DeepEqual(fake.requests, expectedRequests) {
+		t.Errorf("rejected batch should be sent op by op, but requests were %q", fake.requests)
+	}
+	if failures := q.Failures(); len(failures) != 1 || failures[0].Op.Fields["Name"] != "Invalid" {
+		t.Errorf("only invalid operation should fail, but failures were: %+v", failures)
+	}
+}
+
+func TestOpen(t *testing.T) {
+	_, table := newTable(t)
+	path := filepath.Join(t.TempDir(), "queue.journal")
+	q, err := Open(table, path, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Add(map[string]any{"Name": "Loft"}); err != nil {
+		t.Fatal(err)
+	}
+	q.Close()
+
+	// interrupted write of the last entry
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = f.WriteString(`{"op": {"seq": 2, "ty`)
+	f.Close()
+
+	q, err = Open(table, path, Options{})
+	if err != nil {
+		t.Fatalf("must be no error, but was: %v", err)
+	}
+	if q.Depth() != 1 {
+		t.Errorf("expected 1 operation, but was: %d", q.Depth())
+	}
+	if err := q.Update("recA", map[string]any{}); err != nil || q.Pending()[1].Seq != 2 {
+		t.Errorf("seq should continue after the journal, but was: %+v, %v", q.Pending(), err)
+	}
+	q.Close()
+
+	// corrupted entry followed by other entries
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.SplitN(string(b), "\n", 3)
+	if err := os.WriteFile(path, []byte(lines[0]+"\n{\"op\": \n"+lines[2]), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Open(table, path, Options{}); err == nil {
+		t.Error("corrupted journal should be an error")
+	}
+	if err := os.WriteFile(path, b, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	client := airtable.NewClient("apiKey")
+	if _, err := Open(client.GetTable("appXXX", "Districts"), path, Options{}); !errors.Is(err, ErrJournalMismatch) {
+		t.Errorf("should be ErrJournalMismatch, but was: %v", err)
+	}
+}
+
+func TestQueue_DeleteJournalError(t *testing.T) {
+	_, table := newTable(t)
+	q, err := Open(table, filepath.Join(t.TempDir(), "queue.journal"), Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []string{"recA", "recB", "recA"} {
+		if err := q.Update(id, map[string]any{"Name": id}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expected := opTypes(q.Pending())
+	q.journal.file.Close()
+	if err := q.Delete("recA"); err == nil {
+		t.Fatal("should be journal error")
+	}
+	if types := opTypes(q.Pending()); !reflect.DeepEqual(types, expected) {
+		t.Errorf("queue should not be changed, expected %q, but was %q", expected, types)
+	}
+}
+
+func TestTemporary(t *testing.T) {
+	for _, test := range []struct {
+		err       error
+		temporary bool
+	}{
+		{&airtable.HTTPClientError{StatusCode: http.StatusTooManyRequests}, true},
+		{&airtable.HTTPClientError{StatusCode: http.StatusBadGateway}, true},
+		{&airtable.HTTPClientError{StatusCode: http.StatusUnprocessableEntity}, false},
+		{&url.Error{Op: "Post", URL: "/", Err: errors.New("connection refused")}, true},
+		{fmt.Errorf("read: %w", io.ErrUnexpectedEOF), true},
+		{context.DeadlineExceeded, true},
+		{fmt.Errorf("JSON decode failed: %w", &json.SyntaxError{}), false},
+	} {
+		if Temporary(test.err) != test.temporary {
+			t.Errorf("%v: expected temporary %v", test.err, test.temporary)
+		}
+	}
+}
+
+func TestQueue_Run(t *testing.T) {
+	fake, table := newTable(t)
+	q, err := Open(table, filepath.Join(t.TempDir(), "queue.journal"), Options{RetryInterval: time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer q.Close()
+
+	fake.setStatus(http.MethodPost, http.StatusTooManyRequests)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	errs := make(chan error, 100)
+	go func() {
+		defer close(done)
+		q.Run(ctx, func(err error) { errs <- err })
+	}()
+	if err := q.Add(map[string]any{"Name": "Loft"}); err != nil {
+		t.Fatal(err)
+	}
+	<-errs
+	fake.setStatus(http.MethodPost, 0)
+
+	deadline := time.Now().Add(time.Second)
+	for q.Depth() > 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	cancel()
+	<-done
+	if q.Depth() != 0 || len(fake.requests) != 1 {
+		t.Errorf("queue should be flushed after retry, but was: %d, %q", q.Depth(), fake.requests)
+	}
+}
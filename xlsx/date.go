@@ -0,0 +1,79 @@
+// Copyright © 2020 Mike Berezin
+//
+// Use of this source code is governed by an MIT license.
+// Details in the LICENSE file.
+
+package xlsx
+
+import (
+	"math"
+	"strings"
+	"time"
+)
+
+var (
+	// epoch of the 1900 date system, shifted for the Excel 1900 leap year bug.
+	epoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+	// epoch1904 of the 1904 date system.
+	epoch1904 = time.Date(1904, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+// serial converts wall clock of t to days since the epoch.
+func serial(t time.Time) float64 {
+	wall := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), time.UTC)
+	return wall.Sub(epoch).Hours() / 24
+}
+
+// fromSerial converts days since the epoch to UTC time rounded to seconds.
+func fromSerial(days float64, date1904 bool) time.Time {
+	base := epoch
+	if date1904 {
+		base = epoch1904
+	}
+	whole := math.Floor(days)
+	seconds := math.Round((days - whole) * 24 * 60 * 60)
+	return base.AddDate(0, 0, int(whole)).Add(time.Duration(seconds) * time.Second)
+}
+
+// formatSerial formats date as 2006-01-02 and date time as 2006-01-02 15:04:05.
+func formatSerial(days float64, date1904 bool) string {
+	t := fromSerial(days, date1904)
+	if t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 {
+		return t.Format(time.DateOnly)
+	}
+	return t.Format(time.DateTime)
+}
+
+// isDateFormat reports whether number format shows dates or times.
+// Built-in formats 14-22, 27-36, 45-47 and 50-58 are dates,
+// custom formats are dates if they have date or time tokens out of quotes and brackets.
+func isDateFormat(id int, code string) bool {
+	switch {
+	case id >= 14 && id <= 22, id >= 27 && id <= 36, id >= 45 && id <= 47, id >= 50 && id <= 58:
+		return true
+	case id < 164:
+		return false
+	}
+
+	var b strings.Builder
+	inQuotes, inBrackets, escaped := false, false, false
+	for _, r := range code {
+		switch {
+		case escaped:
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '"':
+			inQuotes = !inQuotes
+		case inQuotes:
+		case r == '[':
+			inBrackets = true
+		case r == ']':
+			inBrackets = false
+		case inBrackets:
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return strings.ContainsAny(strings.ToLower(b.String()), "ymdhs")
+}
@@ -0,0 +1,338 @@
+// Copyright © 2020 Mike Berezin
+//
+// Use of this source code is governed by an MIT license.
+// Details in the LICENSE file.
+
+package xlsx
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// ErrSheetNotFound returned when the workbook has no sheet with the name.
+var ErrSheetNotFound = errors.New("xlsx: sheet not found")
+
+// Reader reads sheets of the workbook as rows of text.
+// Numbers are formatted without exponent, booleans as true or false
+// and cells with date formats as 2006-01-02 or 2006-01-02 15:04:05.
+type Reader struct {
+	files      map[string]*zip.File
+	sheets     []sheetRef
+	shared     []string
+	dateStyles []bool
+	date1904   bool
+}
+
+type sheetRef struct {
+	name string
+	path string
+}
+
+// NewReader reads workbook parts from r of the size.
+func NewReader(r io.ReaderAt, size int64) (*Reader, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("xlsx: cannot open workbook: %w", err)
+	}
+	reader := &Reader{files: make(map[string]*zip.File, len(zr.File))}
+	for _, f := range zr.File {
+		reader.files[strings.TrimPrefix(f.Name, "/")] = f
+	}
+	if err := reader.readWorkbook(); err != nil {
+		return nil, err
+	}
+	if err := reader.readSharedStrings(); err != nil {
+		return nil, err
+	}
+	if err := reader.readStyles(); err != nil {
+		return nil, err
+	}
+	return reader, nil
+}
+
+// Sheets returns sheet names in the workbook order.
+func (r *Reader) Sheets() []string {
+	names := make([]string, len(r.sheets))
+	for i, sheet := range r.sheets {
+		names[i] = sheet.name
+	}
+	return names
+}
+
+// Rows opens rows of the sheet.
+// The first sheet is opened if the name is empty.
+func (r *Reader) Rows(name string) (*Rows, error) {
+	var ref *sheetRef
+	for i := range r.sheets {
+		if name == "" || r.sheets[i].name == name {
+			ref = &r.sheets[i]
+			break
+		}
+	}
+	if ref == nil {
+		return nil, fmt.Errorf("%w: %q", ErrSheetNotFound, name)
+	}
+	f, ok := r.files[ref.path]
+	if !ok {
+		return nil, fmt.Errorf("xlsx: sheet %q part %s is missing", ref.name, ref.path)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	return &Rows{reader: r, rc: rc, decoder: xml.NewDecoder(rc)}, nil
+}
+
+func (r *Reader) decode(name string, v any) (bool, error) {
+	f, ok := r.files[name]
+	if !ok {
+		return false, nil
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return false, err
+	}
+	defer rc.Close()
+	if err := xml.NewDecoder(rc).Decode(v); err != nil {
+		return false, fmt.Errorf("xlsx: cannot decode %s: %w", name, err)
+	}
+	return true, nil
+}
+
+// relationships resolves relationship IDs of the part to part names.
+func (r *Reader) relationships(part string) (map[string]string, error) {
+	var rels struct {
+		Relationships []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	dir, file := path.Split(part)
+	if _, err := r.decode(dir+"_rels/"+file+".rels", &rels); err != nil {
+		return nil, err
+	}
+	targets := make(map[string]string, len(rels.Relationships))
+	for _, rel := range rels.Relationships {
+		if strings.HasPrefix(rel.Target, "/") {
+			targets[rel.ID] = strings.TrimPrefix(rel.Target, "/")
+		} else {
+			targets[rel.ID] = path.Join(dir, rel.Target)
+		}
+	}
+	return targets, nil
+}
+
+func (r *Reader) readWorkbook() error {
+	const workbookPart = "xl/workbook.xml"
+	var workbook struct {
+		Properties struct {
+			Date1904 bool `xml:"date1904,attr"`
+		} `xml:"workbookPr"`
+		Sheets []struct {
+			Name string `xml:"name,attr"`
+			ID   string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	ok, err := r.decode(workbookPart, &workbook)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("xlsx: workbook part is missing")
+	}
+	targets, err := r.relationships(workbookPart)
+	if err != nil {
+		return err
+	}
+	r.date1904 = workbook.Properties.Date1904
+	for _, sheet := range workbook.Sheets {
+		r.sheets = append(r.sheets, sheetRef{name: sheet.Name, path: targets[sheet.ID]})
+	}
+	return nil
+}
+
+// richText text of the shared or inline string, plain or with runs.
+type richText struct {
+	T    string `xml:"t"`
+	Runs []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t richText) String() string {
+	if len(t.Runs) == 0 {
+		return t.T
+	}
+	var b strings.Builder
+	for _, run := range t.Runs {
+		b.WriteString(run.T)
+	}
+	return b.String()
+}
+
+func (r *Reader) readSharedStrings() error {
+	var sst struct {
+		Items []richText `xml:"si"`
+	}
+	if _, err := r.decode("xl/sharedStrings.xml", &sst); err != nil {
+		return err
+	}
+	r.shared = make([]string, len(sst.Items))
+	for i, item := range sst.Items {
+		r.shared[i] = item.String()
+	}
+	return nil
+}
+
+func (r *Reader) readStyles() error {
+	var styles struct {
+		NumFmts []struct {
+			ID   int    `xml:"numFmtId,attr"`
+			Code string `xml:"formatCode,attr"`
+		} `xml:"numFmts>numFmt"`
+		CellXfs []struct {
+			NumFmtID int `xml:"numFmtId,attr"`
+		} `xml:"cellXfs>xf"`
+	}
+	if _, err := r.decode("xl/styles.xml", &styles); err != nil {
+		return err
+	}
+	codes := make(map[int]string, len(styles.NumFmts))
+	for _, numFmt := range styles.NumFmts {
+		codes[numFmt.ID] = numFmt.Code
+	}
+	r.dateStyles = make([]bool, len(styles.CellXfs))
+	for i, xf := range styles.CellXfs {
+		r.dateStyles[i] = isDateFormat(xf.NumFmtID, codes[xf.NumFmtID])
+	}
+	return nil
+}
+
+// Rows reads rows of a sheet. Rows satisfies importer.RowReader.
+type Rows struct {
+	reader  *Reader
+	rc      io.ReadCloser
+	decoder *xml.Decoder
+}
+
+type xmlCell struct {
+	Ref    string   `xml:"r,attr"`
+	Type   string   `xml:"t,attr"`
+	Style  int      `xml:"s,attr"`
+	Value  string   `xml:"v"`
+	Inline richText `xml:"is"`
+}
+
+// Read returns the next non-empty row with cells up to the last non-empty one.
+// It returns io.EOF after the last row.
+func (rows *Rows) Read() ([]string, error) {
+	for {
+		token, err := rows.decoder.Token()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil, io.EOF
+			}
+			return nil, fmt.Errorf("xlsx: cannot read sheet: %w", err)
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok || start.Name.Local != "row" {
+			continue
+		}
+		var row struct {
+			Cells []xmlCell `xml:"c"`
+		}
+		if err := rows.decoder.DecodeElement(&row, &start); err != nil {
+			return nil, fmt.Errorf("xlsx: cannot read row: %w", err)
+		}
+		values, err := rows.values(row.Cells)
+		if err != nil {
+			return nil, err
+		}
+		if len(values) > 0 {
+			return values, nil
+		}
+	}
+}
+
+// Close closes the sheet part.
+func (rows *Rows) Close() error {
+	return rows.rc.Close()
+}
+
+func (rows *Rows) values(cells []xmlCell) ([]string, error) {
+	var values []string
+	for i, cell := range cells {
+		index := i
+		if cell.Ref != "" {
+			var err error
+			if index, err = ColumnIndex(cell.Ref); err != nil {
+				return nil, err
+			}
+		}
+		value, err := rows.value(cell)
+		if err != nil {
+			return nil, fmt.Errorf("xlsx: cell %s: %w", cell.Ref, err)
+		}
+		if value == "" {
+			continue
+		}
+		for len(values) <= index {
+			values = append(values, "")
+		}
+		values[index] = value
+	}
+	return values, nil
+}
+
+func (rows *Rows) value(cell xmlCell) (string, error) {
+	switch cell.Type {
+	case "s":
+		i, err := strconv.Atoi(cell.Value)
+		if err != nil || i < 0 || i >= len(rows.reader.shared) {
+			return "", fmt.Errorf("invalid shared string %q", cell.Value)
+		}
+		return rows.reader.shared[i], nil
+	case "inlineStr":
+		return cell.Inline.String(), nil
+	case "b":
+		return strconv.FormatBool(cell.Value == "1"), nil
+	case "str", "e":
+		return cell.Value, nil
+	}
+
+	if cell.Value == "" {
+		return "", nil
+	}
+	f, err := strconv.ParseFloat(cell.Value, 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid number %q", cell.Value)
+	}
+	if cell.Style >= 0 && cell.Style < len(rows.reader.dateStyles) && rows.reader.dateStyles[cell.Style] {
+		return formatSerial(f, rows.reader.date1904), nil
+	}
+	return formatNumber(f), nil
+}
+
+// ColumnIndex returns zero-based column index of cell reference like "AB12".
+func ColumnIndex(ref string) (int, error) {
+	index := 0
+	letters := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		index = index*26 + int(r-'A'+1)
+		letters++
+	}
+	if letters == 0 {
+		return 0, fmt.Errorf("xlsx: invalid cell reference %q", ref)
+	}
+	return index - 1, nil
+}
@@ -0,0 +1,401 @@
+// Copyright © 2020 Mike Berezin
+//
+// Use of this source code is governed by an MIT license.
+// Details in the LICENSE file.
+
+// Package xlsx reads and writes simple Office Open XML workbooks:
+// sheets of rows with text, number, boolean, date and hyperlink cells.
+// Formatting beyond number formats of dates is not supported.
+package xlsx
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+const (
+	nsMain                 = "http://schemas.openxmlformats.org/spreadsheetml/2006/main"
+	nsRelationships        = "http://schemas.openxmlformats.org/officeDocument/2006/relationships"
+	nsPackageRelationships = "http://schemas.openxmlformats.org/package/2006/relationships"
+
+	// maxSheetNameLength Excel limit of the sheet name.
+	maxSheetNameLength = 31
+	// maxCellLength Excel limit of the cell text in UTF-16 code units.
+	maxCellLength = 32767
+)
+
+// ErrClosed returned when writing to the closed sheet or workbook.
+var ErrClosed = errors.New("xlsx: write to closed sheet")
+
+type cellKind int
+
+const (
+	kindEmpty cellKind = iota
+	kindString
+	kindNumber
+	kindBool
+	kindDate
+	kindDateTime
+)
+
+// styles of cellXfs in styles.xml.
+const (
+	styleDefault  = 0
+	styleDate     = 1
+	styleDateTime = 2
+	styleLink     = 3
+)
+
+// Cell value to write. The zero Cell is an empty cell.
+type Cell struct {
+	kind   cellKind
+	text   string
+	number float64
+	link   string
+}
+
+// String text cell, text longer than Excel limit of 32767 characters is truncated.
+func String(s string) Cell {
+	if s == "" {
+		return Cell{}
+	}
+	return Cell{kind: kindString, text: truncateCell(s)}
+}
+
+// Number numeric cell, NaN and infinities are written as text.
+func Number(f float64) Cell {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return String(strconv.FormatFloat(f, 'f', -1, 64))
+	}
+	return Cell{kind: kindNumber, number: f}
+}
+
+// Bool boolean cell.
+func Bool(b bool) Cell {
+	cell := Cell{kind: kindBool}
+	if b {
+		cell.number = 1
+	}
+	return cell
+}
+
+// Date date cell formatted as yyyy-mm-dd, the time of t is dropped.
+func Date(t time.Time) Cell {
+	return Cell{kind: kindDate, number: math.Floor(serial(t))}
+}
+
+// DateTime date time cell formatted as yyyy-mm-dd hh:mm:ss
+// with the wall clock of t, Excel dates have no time zone.
+func DateTime(t time.Time) Cell {
+	return Cell{kind: kindDateTime, number: serial(t)}
+}
+
+// Link text cell with hyperlink to url.
+func Link(text, url string) Cell {
+	if text == "" {
+		text = url
+	}
+	return Cell{kind: kindString, text: truncateCell(text), link: url}
+}
+
+// Writer writes workbook sheet by sheet.
+type Writer struct {
+	zw     *zip.Writer
+	sheets []string
+	sheet  *Sheet
+	closed bool
+}
+
+// NewWriter creates workbook writer to w.
+// Close must be called to write the workbook parts.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{zw: zip.NewWriter(w)}
+}
+
+// NewSheet finishes the previous sheet and starts a new one.
+// The name is cut to 31 characters, characters not allowed by Excel are replaced
+// and a number is added if the sheet with the name already exists.
+func (w *Writer) NewSheet(name string) (*Sheet, error) {
+	if w.closed {
+		return nil, ErrClosed
+	}
+	if err := w.closeSheet(); err != nil {
+		return nil, err
+	}
+
+	name = w.uniqueName(sheetName(name))
+	w.sheets = append(w.sheets, name)
+	index := len(w.sheets)
+
+	fw, err := w.zw.Create(fmt.Sprintf("xl/worksheets/sheet%d.xml", index))
+	if err != nil {
+		return nil, err
+	}
+	sheet := &Sheet{
+		name:  name,
+		index: index,
+		w:     bufio.NewWriter(fw),
+	}
+	if _, err := sheet.w.WriteString(xml.Header + `<worksheet xmlns="` + nsMain + `" xmlns:r="` + nsRelationships + `"><sheetData>`); err != nil {
+		return nil, err
+	}
+	w.sheet = sheet
+	return sheet, nil
+}
+
+// Close finishes the last sheet and writes the workbook parts.
+// It does not close the underlying writer.
+func (w *Writer) Close() error {
+	if w.closed {
+		return nil
+	}
+	if err := w.closeSheet(); err != nil {
+		return err
+	}
+	w.closed = true
+	if len(w.sheets) == 0 {
+		// Excel does not open workbooks without sheets.
+		w.sheets = append(w.sheets, "Sheet1")
+		if err := w.writePart("xl/worksheets/sheet1.xml", `<worksheet xmlns="`+nsMain+`"><sheetData/></worksheet>`); err != nil {
+			return err
+		}
+	}
+
+	var contentTypes, workbook, workbookRels strings.Builder
+	contentTypes.WriteString(`<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+		`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+		`<Default Extension="xml" ContentType="application/xml"/>` +
+		`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+		`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>`)
+	workbook.WriteString(`<workbook xmlns="` + nsMain + `" xmlns:r="` + nsRelationships + `"><sheets>`)
+	workbookRels.WriteString(`<Relationships xmlns="` + nsPackageRelationships + `">` +
+		`<Relationship Id="rIdStyles" Type="` + nsRelationships + `/styles" Target="styles.xml"/>`)
+	for i, name := range w.sheets {
+		fmt.Fprintf(&contentTypes, `<Override PartName="/xl/worksheets/sheet%d.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>`, i+1)
+		fmt.Fprintf(&workbook, `<sheet name="%s" sheetId="%d" r:id="rId%d"/>`, escape(name), i+1, i+1)
+		fmt.Fprintf(&workbookRels, `<Relationship Id="rId%d" Type="%s/worksheet" Target="worksheets/sheet%d.xml"/>`, i+1, nsRelationships, i+1)
+	}
+	contentTypes.WriteString(`</Types>`)
+	workbook.WriteString(`</sheets></workbook>`)
+	workbookRels.WriteString(`</Relationships>`)
+
+	for _, part := range []struct{ name, content string }{
+		{"[Content_Types].xml", contentTypes.String()},
+		{"_rels/.rels", `<Relationships xmlns="` + nsPackageRelationships + `">` +
+			`<Relationship Id="rId1" Type="` + nsRelationships + `/officeDocument" Target="xl/workbook.xml"/></Relationships>`},
+		{"xl/workbook.xml", workbook.String()},
+		{"xl/_rels/workbook.xml.rels", workbookRels.String()},
+		{"xl/styles.xml", stylesXML},
+	} {
+		if err := w.writePart(part.name, part.content); err != nil {
+			return err
+		}
+	}
+	return w.zw.Close()
+}
+
+func (w *Writer) writePart(name, content string) error {
+	fw, err := w.zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(fw, xml.Header+content)
+	return err
+}
+
+// closeSheet finishes sheet data and writes its hyperlinks.
+func (w *Writer) closeSheet() error {
+	sheet := w.sheet
+	if sheet == nil {
+		return nil
+	}
+	w.sheet = nil
+	sheet.closed = true
+
+	sheet.w.WriteString(`</sheetData>`)
+	if len(sheet.links) > 0 {
+		sheet.w.WriteString(`<hyperlinks>`)
+		for i, link := range sheet.links {
+			fmt.Fprintf(sheet.w, `<hyperlink ref="%s" r:id="rId%d"/>`, link.ref, i+1)
+		}
+		sheet.w.WriteString(`</hyperlinks>`)
+	}
+	sheet.w.WriteString(`</worksheet>`)
+	if err := sheet.w.Flush(); err != nil {
+		return err
+	}
+
+	if len(sheet.links) == 0 {
+		return nil
+	}
+	var rels strings.Builder
+	rels.WriteString(`<Relationships xmlns="` + nsPackageRelationships + `">`)
+	for i, link := range sheet.links {
+		fmt.Fprintf(&rels, `<Relationship Id="rId%d" Type="%s/hyperlink" Target="%s" TargetMode="External"/>`, i+1, nsRelationships, escape(link.url))
+	}
+	rels.WriteString(`</Relationships>`)
+	return w.writePart(fmt.Sprintf("xl/worksheets/_rels/sheet%d.xml.rels", sheet.index), rels.String())
+}
+
+func (w *Writer) uniqueName(name string) string {
+	exists := func(name string) bool {
+		for _, sheet := range w.sheets {
+			if strings.EqualFold(sheet, name) {
+				return true
+			}
+		}
+		return false
+	}
+	unique := name
+	for i := 2; exists(unique); i++ {
+		suffix := " (" + strconv.Itoa(i) + ")"
+		unique = truncate(name, maxSheetNameLength-len(suffix)) + suffix
+	}
+	return unique
+}
+
+// Sheet writes rows of one sheet.
+type Sheet struct {
+	name   string
+	index  int
+	w      *bufio.Writer
+	row    int
+	links  []hyperlink
+	closed bool
+}
+
+type hyperlink struct {
+	ref string
+	url string
+}
+
+// Name of the sheet in the workbook.
+func (s *Sheet) Name() string {
+	return s.name
+}
+
+// Write writes row of text cells. Sheet satisfies importer.RowWriter.
+func (s *Sheet) Write(row []string) error {
+	cells := make([]Cell, len(row))
+	for i, value := range row {
+		cells[i] = String(value)
+	}
+	return s.WriteCells(cells)
+}
+
+// WriteCells writes row of cells.
+func (s *Sheet) WriteCells(cells []Cell) error {
+	if s.closed {
+		return ErrClosed
+	}
+	s.row++
+	fmt.Fprintf(s.w, `<row r="%d">`, s.row)
+	for i, cell := range cells {
+		ref := ColumnName(i) + strconv.Itoa(s.row)
+		switch cell.kind {
+		case kindEmpty:
+			continue
+		case kindString:
+			style := styleDefault
+			if cell.link != "" {
+				style = styleLink
+				s.links = append(s.links, hyperlink{ref: ref, url: cell.link})
+			}
+			fmt.Fprintf(s.w, `<c r="%s" s="%d" t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, style, escape(cell.text))
+		case kindNumber:
+			fmt.Fprintf(s.w, `<c r="%s"><v>%s</v></c>`, ref, formatNumber(cell.number))
+		case kindBool:
+			fmt.Fprintf(s.w, `<c r="%s" t="b"><v>%s</v></c>`, ref, formatNumber(cell.number))
+		case kindDate:
+			fmt.Fprintf(s.w, `<c r="%s" s="%d"><v>%s</v></c>`, ref, styleDate, formatNumber(cell.number))
+		case kindDateTime:
+			fmt.Fprintf(s.w, `<c r="%s" s="%d"><v>%s</v></c>`, ref, styleDateTime, formatNumber(cell.number))
+		}
+	}
+	_, err := s.w.WriteString(`</row>`)
+	return err
+}
+
+// ColumnName returns column letters of zero-based index: A, B, ..., Z, AA.
+func ColumnName(index int) string {
+	name := ""
+	for index++; index > 0; index = (index - 1) / 26 {
+		name = string(rune('A'+(index-1)%26)) + name
+	}
+	return name
+}
+
+// escape escapes text for XML, invalid XML characters are replaced with U+FFFD.
+func escape(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+func formatNumber(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// sheetName replaces characters not allowed in sheet names and cuts it to 31 characters.
+func sheetName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '_'
+		}
+		return r
+	}, strings.Trim(name, "'"))
+	if name == "" {
+		name = "Sheet"
+	}
+	return truncate(name, maxSheetNameLength)
+}
+
+// truncateCell cuts s to maxCellLength UTF-16 code units,
+// which Excel counts as characters.
+func truncateCell(s string) string {
+	length := 0
+	for i, r := range s {
+		n := utf16.RuneLen(r)
+		if n < 0 {
+			n = 1
+		}
+		if length+n > maxCellLength {
+			return s[:i]
+		}
+		length += n
+	}
+	return s
+}
+
+// truncate cuts s to n runes.
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) > n {
+		return string(runes[:n])
+	}
+	return s
+}
+
+const stylesXML = `<styleSheet xmlns="` + nsMain + `">` +
+	`<numFmts count="2"><numFmt numFmtId="164" formatCode="yyyy-mm-dd"/><numFmt numFmtId="165" formatCode="yyyy-mm-dd hh:mm:ss"/></numFmts>` +
+	`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><u/><sz val="11"/><color rgb="FF0563C1"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="4">` +
+	`<xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/>` +
+	`<xf numFmtId="164" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="165" fontId="0" fillId="0" borderId="0" xfId="0" applyNumberFormat="1"/>` +
+	`<xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/>` +
+	`</cellXfs>` +
+	`<cellStyles count="1"><cellStyle name="Normal" xfId="0" builtinId="0"/></cellStyles>` +
+	`</styleSheet>`
@@ -0,0 +1,199 @@
+// Copyright © 2020 Mike Berezin
+//
+// Use of this source code is governed by an MIT license.
+// Details in the LICENSE file.
+
+package xlsx
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func readAll(t *testing.T, r *Reader, sheet string) [][]string {
+	t.Helper()
+	rows, err := r.Rows(sheet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	var all [][]string
+	for {
+		row, err := rows.Read()
+		if errors.Is(err, io.EOF) {
+			return all
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		all = append(all, row)
+	}
+}
+
+func TestWriterReader(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+
+	sheet, err := w.NewSheet("Apartments: 2020/04")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sheet.Write([]string{"Name", "Price", "Sold", "Listed", "Visited", "Photo"}); err != nil {
+		t.Fatal(err)
+	}
+	err = sheet.WriteCells([]Cell{
+		String("Loft <1> & \"2\""),
+		Number(1234.5),
+		Bool(true),
+		Date(time.Date(2020, 4, 6, 15, 0, 0, 0, time.UTC)),
+		DateTime(time.Date(2020, 4, 6, 9, 30, 15, 0, time.FixedZone("MSK", 3*60*60))),
+		Link("1.jpg", "https://example.com/1.jpg?a=1&b=2"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sheet.WriteCells([]Cell{String("Studio"), {}, Bool(false)}); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := w.NewSheet("apartments: 2020/04"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := sheet.Write([]string{"closed"}); !errors.Is(err, ErrClosed) {
+		t.Errorf("expected ErrClosed, got %v", err)
+	}
+
+	r, err := NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sheets := r.Sheets(); !reflect.DeepEqual(sheets, []string{"Apartments_ 2020_04", "apartments_ 2020_04 (2)"}) {
+		t.Errorf("unexpected sheets %q", sheets)
+	}
+
+	expected := [][]string{
+		{"Name", "Price", "Sold", "Listed", "Visited", "Photo"},
+		{"Loft <1> & \"2\"", "1234.5", "true", "2020-04-06", "2020-04-06 09:30:15", "1.jpg"},
+		{"Studio", "", "false"},
+	}
+	if rows := readAll(t, r, ""); !reflect.DeepEqual(rows, expected) {
+		t.Errorf("expected %q, got %q", expected, rows)
+	}
+	if rows := readAll(t, r, "apartments_ 2020_04 (2)"); len(rows) != 0 {
+		t.Errorf("expected empty sheet, got %q", rows)
+	}
+	if _, err := r.Rows("Unknown"); !errors.Is(err, ErrSheetNotFound) {
+		t.Errorf("expected ErrSheetNotFound, got %v", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range zr.File {
+		if f.Name != "xl/worksheets/_rels/sheet1.xml.rels" {
+			continue
+		}
+		rc, _ := f.Open()
+		b, _ := io.ReadAll(rc)
+		rc.Close()
+		if !strings.Contains(string(b), `Target="https://example.com/1.jpg?a=1&amp;b=2" TargetMode="External"`) {
+			t.Errorf("unexpected hyperlink relationships %s", b)
+		}
+		return
+	}
+	t.Error("hyperlink relationships not found")
+}
+
+func TestWriter_Limits(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	sheet, err := w.NewSheet("Limits")
+	if err != nil {
+		t.Fatal(err)
+	}
+	long := strings.Repeat("a", maxCellLength-1) + "😀"
+	err = sheet.WriteCells([]Cell{String(long), Number(math.NaN()), Number(math.Inf(-1)), Link(long, "https://example.com")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows := readAll(t, r, "")
+	truncated := long[:maxCellLength-1]
+	if len(rows) != 1 || !reflect.DeepEqual(rows[0], []string{truncated, "NaN", "-Inf", truncated}) {
+		t.Errorf("long text should be truncated and not finite numbers written as text, but was: %.20q", rows)
+	}
+}
+
+// TestReader_Excel reads workbook parts as Excel writes them:
+// shared strings, rich text, sparse cells, 1904 dates and custom formats.
+func TestReader_Excel(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range map[string]string{
+		"xl/workbook.xml": `<workbook xmlns="` + nsMain + `" xmlns:r="` + nsRelationships + `">` +
+			`<workbookPr date1904="1"/><sheets><sheet name="Data" sheetId="1" r:id="rId3"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="` + nsPackageRelationships + `">` +
+			`<Relationship Id="rId3" Type="` + nsRelationships + `/worksheet" Target="/xl/worksheets/data.xml"/></Relationships>`,
+		"xl/sharedStrings.xml": `<sst xmlns="` + nsMain + `"><si><t>Name</t></si><si><r><t>Lo</t></r><r><t>ft</t></r></si></sst>`,
+		"xl/styles.xml": `<styleSheet xmlns="` + nsMain + `"><numFmts><numFmt numFmtId="170" formatCode="[$-409]d\-mmm\-yy;@"/>` +
+			`<numFmt numFmtId="171" formatCode="#,##0.00&quot;days&quot;"/></numFmts>` +
+			`<cellXfs><xf numFmtId="0"/><xf numFmtId="170"/><xf numFmtId="171"/><xf numFmtId="14"/></cellXfs></styleSheet>`,
+		"xl/worksheets/data.xml": `<worksheet xmlns="` + nsMain + `"><sheetData>` +
+			`<row r="1"><c r="A1" t="s"><v>0</v></c><c r="C1" t="s"><v>0</v></c></row>` +
+			`<row r="2"/>` +
+			`<row r="3"><c r="A3" t="s"><v>1</v></c><c r="B3" s="1"><v>0</v></c><c r="C3" s="2"><v>1.5E-2</v></c><c r="D3" s="3"><v>1</v></c></row>` +
+			`</sheetData></worksheet>`,
+	} {
+		fw, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := io.WriteString(fw, content); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := [][]string{
+		{"Name", "", "Name"},
+		{"Loft", "1904-01-01", "0.015", "1904-01-02"},
+	}
+	if rows := readAll(t, r, "Data"); !reflect.DeepEqual(rows, expected) {
+		t.Errorf("expected %q, got %q", expected, rows)
+	}
+}
+
+func TestColumnName(t *testing.T) {
+	for index, name := range map[int]string{0: "A", 25: "Z", 26: "AA", 27: "AB", 701: "ZZ", 702: "AAA"} {
+		if got := ColumnName(index); got != name {
+			t.Errorf("ColumnName(%d) = %q, expected %q", index, got, name)
+		}
+		if got, err := ColumnIndex(name + "12"); err != nil || got != index {
+			t.Errorf("ColumnIndex(%q) = %d, %v, expected %d", name+"12", got, err, index)
+		}
+	}
+}